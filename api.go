@@ -0,0 +1,490 @@
+package vers
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Bump levels recommended by DiffAPI
+const (
+	BumpLevelMajor = "major"
+	BumpLevelMinor = "minor"
+	BumpLevelPatch = "patch"
+)
+
+// APIChange is a change to an exported Go declaration
+type APIChange struct {
+	// Package is the directory of the package, relative to the repository root
+	Package string `json:"package"`
+
+	// Object is the changed declaration: a name, or Type.Member for methods
+	// and struct fields. It is empty for added and removed packages.
+	Object string `json:"object,omitempty"`
+
+	Message    string `json:"message"`
+	Compatible bool   `json:"compatible"`
+}
+
+func (c APIChange) String() string {
+	switch {
+	case c.Object == "":
+		return fmt.Sprintf("%s: %s", c.Package, c.Message)
+	case c.Package == ".":
+		return fmt.Sprintf("%s: %s", c.Object, c.Message)
+	default:
+		return fmt.Sprintf("%s.%s: %s", c.Package, c.Object, c.Message)
+	}
+}
+
+// APIReport lists the exported API changes between two commits and the
+// bump level they call for
+type APIReport struct {
+	Bump    string      `json:"bump"`
+	Changes []APIChange `json:"changes"`
+}
+
+// Incompatible returns the changes that can break importers
+func (r *APIReport) Incompatible() []APIChange {
+	var changes []APIChange
+	for _, change := range r.Changes {
+		if !change.Compatible {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// DiffAPI compares the exported API of the Go packages under dir (the
+// repository root if empty) at two commits. Removed or changed
+// declarations are incompatible and call for a major bump, additions call
+// for a minor bump, and an unchanged API for a patch bump.
+//
+// Packages are compared by their declarations as written, so changes that
+// only type checking reveals, such as a changed constant value or an
+// inferred variable type, are not reported. Tests, main packages and
+// internal, testdata and vendor directories are skipped.
+func DiffAPI(repo *git.Repository, from, to plumbing.Hash, dir string) (*APIReport, error) {
+	return diffAPI(context.Background(), repo, from, to, dir)
+}
+
+func diffAPI(ctx context.Context, repo *git.Repository, from, to plumbing.Hash, dir string) (*APIReport, error) {
+	before, err := commitAPI(ctx, repo, from, dir)
+	if err != nil {
+		return nil, err
+	}
+	after, err := commitAPI(ctx, repo, to, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &APIReport{Changes: diffPackageAPIs(before, after)}
+
+	report.Bump = BumpLevelPatch
+	for _, change := range report.Changes {
+		if !change.Compatible {
+			report.Bump = BumpLevelMajor
+			break
+		}
+		report.Bump = BumpLevelMinor
+	}
+
+	return report, nil
+}
+
+// bumpLevel increments a version by a bump level. Below 1.0.0 each level
+// shifts down one, so incompatible changes bump the minor version and
+// anything else the patch version, as Go modules expect of v0.
+func bumpLevel(version semver.Version, level string) semver.Version {
+	if version.Major == 0 {
+		switch level {
+		case BumpLevelMajor:
+			level = BumpLevelMinor
+		case BumpLevelMinor:
+			level = BumpLevelPatch
+		}
+	}
+
+	switch level {
+	case BumpLevelMajor:
+		version.Major++
+		version.Minor, version.Patch = 0, 0
+	case BumpLevelMinor:
+		version.Minor++
+		version.Patch = 0
+	default:
+		version.Patch++
+	}
+
+	return version
+}
+
+// apiDir returns the module directory of a tag such as sdk/v1.2.0, empty
+// for tags of the root module
+func apiDir(tag string) string {
+	dir, _ := path.Split(tag)
+	return strings.TrimSuffix(dir, "/")
+}
+
+// describeAPIReport summarizes the changes in an API report
+func describeAPIReport(report *APIReport) string {
+	incompatible := len(report.Incompatible())
+	compatible := len(report.Changes) - incompatible
+
+	switch {
+	case incompatible > 0 && compatible > 0:
+		return countAPIChanges(incompatible, "incompatible") + " and " + countAPIChanges(compatible, "compatible")
+	case incompatible > 0:
+		return countAPIChanges(incompatible, "incompatible")
+	case compatible > 0:
+		return countAPIChanges(compatible, "compatible")
+	default:
+		return "no API changes"
+	}
+}
+
+// countAPIChanges describes a number of API changes of a kind, e.g.
+// "1 compatible API change"
+func countAPIChanges(count int, kind string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s API change", count, kind)
+	}
+	return fmt.Sprintf("%d %s API changes", count, kind)
+}
+
+// packageAPI maps exported declarations to a description of their types
+type packageAPI map[string]string
+
+// commitAPI reads the exported API of each package in the commit's tree
+func commitAPI(ctx context.Context, repo *git.Repository, hash plumbing.Hash, dir string) (map[string]packageAPI, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("getting tree of %s: %w", hash, err)
+	}
+
+	apis := make(map[string]packageAPI)
+	fset := token.NewFileSet()
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !isAPISource(file.Name, dir) {
+			return nil
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file.Name, err)
+		}
+		defer reader.Close()
+
+		src, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file.Name, err)
+		}
+
+		parsed, err := parser.ParseFile(fset, file.Name, src, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("parsing %s at %.8s: %w", file.Name, hash, err)
+		}
+		if parsed.Name.Name == "main" {
+			return nil
+		}
+
+		pkg := path.Dir(file.Name)
+		if apis[pkg] == nil {
+			apis[pkg] = make(packageAPI)
+		}
+		addFileAPI(apis[pkg], parsed)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return apis, nil
+}
+
+// isAPISource reports whether a file contributes to the importable API of
+// the packages under dir
+func isAPISource(name, dir string) bool {
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+	if dir != "" && !strings.HasPrefix(name, strings.TrimSuffix(dir, "/")+"/") {
+		return false
+	}
+
+	parts := strings.Split(name, "/")
+	for _, part := range parts[:len(parts)-1] {
+		switch {
+		case part == "internal", part == "testdata", part == "vendor":
+			return false
+		case strings.HasPrefix(part, "."), strings.HasPrefix(part, "_"):
+			return false
+		}
+	}
+
+	return true
+}
+
+// addFileAPI records the exported declarations of a file
+func addFileAPI(api packageAPI, file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			if decl.Recv == nil {
+				api[decl.Name.Name] = "func" + typeParams(decl.Type.TypeParams) + signature(decl.Type)
+				continue
+			}
+
+			receiver := decl.Recv.List[0].Type
+			pointer := ""
+			if star, ok := receiver.(*ast.StarExpr); ok {
+				receiver, pointer = star.X, "*"
+			}
+			name := baseTypeName(receiver)
+			if ast.IsExported(name) {
+				api[name+"."+decl.Name.Name] = fmt.Sprintf("method (%s%s)%s", pointer, name, signature(decl.Type))
+			}
+		case *ast.GenDecl:
+			addGenDeclAPI(api, decl)
+		}
+	}
+}
+
+func addGenDeclAPI(api packageAPI, decl *ast.GenDecl) {
+	// Constants without a type or value repeat the previous specification
+	var constType ast.Expr
+
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			if !spec.Name.IsExported() {
+				continue
+			}
+			name := spec.Name.Name
+			params := typeParams(spec.TypeParams)
+
+			switch t := spec.Type.(type) {
+			case *ast.StructType:
+				api[name] = "type" + params + " struct"
+				for _, field := range t.Fields.List {
+					for _, fieldName := range fieldNames(field) {
+						if ast.IsExported(fieldName) {
+							api[name+"."+fieldName] = "field " + types.ExprString(field.Type)
+						}
+					}
+				}
+			case *ast.InterfaceType:
+				api[name] = "type" + params + " " + interfaceString(t)
+			default:
+				if spec.Assign.IsValid() {
+					api[name] = "type" + params + " = " + types.ExprString(spec.Type)
+				} else {
+					api[name] = "type" + params + " " + types.ExprString(spec.Type)
+				}
+			}
+		case *ast.ValueSpec:
+			kind := "var"
+			if decl.Tok == token.CONST {
+				kind = "const"
+				if spec.Type != nil || len(spec.Values) > 0 {
+					constType = spec.Type
+				}
+			}
+
+			valueType := spec.Type
+			if decl.Tok == token.CONST {
+				valueType = constType
+			}
+
+			description := kind
+			if valueType != nil {
+				description += " " + types.ExprString(valueType)
+			}
+			for _, name := range spec.Names {
+				if name.IsExported() {
+					api[name.Name] = description
+				}
+			}
+		}
+	}
+}
+
+// fieldNames returns the names of a struct field, or the type name of an
+// embedded field
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		embedded := field.Type
+		if star, ok := embedded.(*ast.StarExpr); ok {
+			embedded = star.X
+		}
+		return []string{baseTypeName(embedded)}
+	}
+
+	names := make([]string, len(field.Names))
+	for i, name := range field.Names {
+		names[i] = name.Name
+	}
+	return names
+}
+
+// baseTypeName returns the name of a possibly generic or qualified type
+func baseTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return baseTypeName(t.X)
+	case *ast.IndexListExpr:
+		return baseTypeName(t.X)
+	}
+	return types.ExprString(expr)
+}
+
+// signature formats the parameter and result types of a function, leaving
+// out names since renaming a parameter doesn't affect callers
+func signature(fn *ast.FuncType) string {
+	result := "(" + strings.Join(fieldTypes(fn.Params), ", ") + ")"
+
+	results := fieldTypes(fn.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		result += " " + results[0]
+	default:
+		result += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	return result
+}
+
+// typeParams formats type parameter constraints, without their names
+func typeParams(params *ast.FieldList) string {
+	if params == nil || len(params.List) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(fieldTypes(params), ", ") + "]"
+}
+
+// fieldTypes lists the type of each name in a field list
+func fieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+
+	var result []string
+	for _, field := range fields.List {
+		count := max(len(field.Names), 1)
+		for range count {
+			result = append(result, types.ExprString(field.Type))
+		}
+	}
+	return result
+}
+
+// interfaceString formats an interface with its exported methods and
+// embedded types sorted, since any change to the method set of an
+// interface breaks either its callers or its implementations
+func interfaceString(iface *ast.InterfaceType) string {
+	var elements []string
+	for _, method := range iface.Methods.List {
+		fn, ok := method.Type.(*ast.FuncType)
+		switch {
+		case ok && method.Names[0].IsExported():
+			elements = append(elements, method.Names[0].Name+signature(fn))
+		case ok:
+			elements = append(elements, "unexported methods")
+		default:
+			elements = append(elements, types.ExprString(method.Type))
+		}
+	}
+
+	sort.Strings(elements)
+	elements = compactStrings(elements)
+
+	return "interface{" + strings.Join(elements, "; ") + "}"
+}
+
+func compactStrings(values []string) []string {
+	var result []string
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// diffPackageAPIs lists the changes between two sets of package APIs,
+// sorted by package and declaration
+func diffPackageAPIs(before, after map[string]packageAPI) []APIChange {
+	var changes []APIChange
+
+	for pkg, api := range before {
+		newAPI, ok := after[pkg]
+		if !ok {
+			changes = append(changes, APIChange{Package: pkg, Message: "package removed"})
+			continue
+		}
+
+		for name, description := range api {
+			newDescription, ok := newAPI[name]
+			switch {
+			case !ok:
+				changes = append(changes, APIChange{Package: pkg, Object: name, Message: "removed"})
+			case newDescription != description:
+				changes = append(changes, APIChange{
+					Package: pkg,
+					Object:  name,
+					Message: fmt.Sprintf("changed from %s to %s", description, newDescription),
+				})
+			}
+		}
+
+		for name := range newAPI {
+			if _, ok := api[name]; !ok {
+				changes = append(changes, APIChange{Package: pkg, Object: name, Message: "added", Compatible: true})
+			}
+		}
+	}
+
+	for pkg := range after {
+		if _, ok := before[pkg]; !ok {
+			changes = append(changes, APIChange{Package: pkg, Message: "package added", Compatible: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Package != changes[j].Package {
+			return changes[i].Package < changes[j].Package
+		}
+		return changes[i].Object < changes[j].Object
+	})
+
+	return changes
+}
@@ -0,0 +1,268 @@
+package vers
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func testParseAPI(t *testing.T, src string) packageAPI {
+	file, err := parser.ParseFile(token.NewFileSet(), "api.go", "package api\n"+src, parser.SkipObjectResolution)
+	require.NoError(t, err)
+
+	api := make(packageAPI)
+	addFileAPI(api, file)
+	return api
+}
+
+func TestFileAPI(t *testing.T) {
+	api := testParseAPI(t, `
+import "io"
+
+const (
+	A Level = iota
+	B
+	c
+)
+
+const Name = "vers"
+
+var Default = New(nil)
+
+type Level int
+
+type Client struct {
+	io.Reader
+	Name    string
+	timeout int
+}
+
+type Doer interface {
+	Do(ctx, name string) (int, error)
+	io.Closer
+}
+
+type Alias = Client
+
+type List[T comparable] []T
+
+func New(w io.Writer, opts ...string) *Client { return nil }
+
+func (c *Client) Get(key string) string { return "" }
+
+func (c Client) close() {}
+
+func helper() {}
+`)
+
+	require.Equal(t, packageAPI{
+		"A":             "const Level",
+		"B":             "const Level",
+		"Name":          "const",
+		"Default":       "var",
+		"Level":         "type int",
+		"Client":        "type struct",
+		"Client.Reader": "field io.Reader",
+		"Client.Name":   "field string",
+		"Doer":          "type interface{Do(string, string) (int, error); io.Closer}",
+		"Alias":         "type = Client",
+		"List":          "type[comparable] []T",
+		"New":           "func(io.Writer, ...string) *Client",
+		"Client.Get":    "method (*Client)(string) string",
+	}, api)
+}
+
+func TestDiffPackageAPIs(t *testing.T) {
+	before := map[string]packageAPI{
+		".":      testParseAPI(t, "func Get(key string) string\nfunc Put(key, value string)\ntype Doer interface{ Do() }"),
+		"legacy": testParseAPI(t, "func Old()"),
+	}
+	after := map[string]packageAPI{
+		".":   testParseAPI(t, "func Get(name string) string\nfunc Put(key string, value []byte)\nfunc Delete(key string)\ntype Doer interface{ Do(); Undo() }"),
+		"sdk": testParseAPI(t, "func New()"),
+	}
+
+	var changes []string
+	for _, change := range diffPackageAPIs(before, after) {
+		changes = append(changes, change.String())
+	}
+
+	require.Equal(t, []string{
+		"Delete: added",
+		"Doer: changed from type interface{Do()} to type interface{Do(); Undo()}",
+		"Put: changed from func(string, string) to func(string, []byte)",
+		"legacy: package removed",
+		"sdk: package added",
+	}, changes)
+}
+
+func TestIsAPISource(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		expected bool
+	}{
+		{"version.go", "", true},
+		{"cmd/main.go", "", true},
+		{"version_test.go", "", false},
+		{"README.md", "", false},
+		{"internal/parse/parse.go", "", false},
+		{"pkg/testdata/fixture.go", "", false},
+		{"vendor/github.com/x/y.go", "", false},
+		{".github/tools.go", "", false},
+		{"sdk/client.go", "sdk", true},
+		{"client.go", "sdk", false},
+		{"sdkx/client.go", "sdk", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, isAPISource(test.name, test.dir))
+		})
+	}
+}
+
+func TestBumpLevel(t *testing.T) {
+	tests := []struct {
+		version  string
+		level    string
+		expected string
+	}{
+		{"1.2.3", BumpLevelMajor, "2.0.0"},
+		{"1.2.3", BumpLevelMinor, "1.3.0"},
+		{"1.2.3", BumpLevelPatch, "1.2.4"},
+		{"0.2.3", BumpLevelMajor, "0.3.0"},
+		{"0.2.3", BumpLevelMinor, "0.2.4"},
+		{"0.2.3", BumpLevelPatch, "0.2.4"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version+" "+test.level, func(t *testing.T) {
+			require.Equal(t, test.expected, bumpLevel(semver.MustParse(test.version), test.level).String())
+		})
+	}
+}
+
+func TestDescribeAPIReport(t *testing.T) {
+	tests := []struct {
+		incompatible int
+		compatible   int
+		expected     string
+	}{
+		{0, 0, "no API changes"},
+		{0, 1, "1 compatible API change"},
+		{0, 2, "2 compatible API changes"},
+		{1, 0, "1 incompatible API change"},
+		{1, 1, "1 incompatible API change and 1 compatible API change"},
+		{2, 3, "2 incompatible API changes and 3 compatible API changes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			report := &APIReport{}
+			for i := 0; i < test.incompatible; i++ {
+				report.Changes = append(report.Changes, APIChange{Compatible: false})
+			}
+			for i := 0; i < test.compatible; i++ {
+				report.Changes = append(report.Changes, APIChange{Compatible: true})
+			}
+			require.Equal(t, test.expected, describeAPIReport(report))
+		})
+	}
+}
+
+// testRepoAPIHistory creates a repository tagged v1.2.0 with one Go
+// package, then commits the given source for it
+func testRepoAPIHistory(t *testing.T, src string) *git.Repository {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	addFile(t, workTree, "client/client.go", "package client\n\nfunc Get(key string) string { return key }\n")
+	addFile(t, workTree, "cmd/tool/main.go", "package main\n\nfunc main() {}\n")
+	hash, err := workTree.Commit("Release", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.2.0", hash, nil)
+	require.NoError(t, err)
+
+	addFile(t, workTree, "client/client.go", src)
+	addFile(t, workTree, "cmd/tool/main.go", "package main\n\nfunc main() {}\n\nfunc Run() {}\n")
+	_, err = workTree.Commit("Change API", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestCalculateWithAPIBump(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		bump     string
+		expected string
+	}{
+		{"Unchanged API", "package client\n\n// Get returns key\nfunc Get(k string) string { return k + \"\" }\n", BumpLevelPatch, "1.2.1-alpha"},
+		{"Added function", "package client\n\nfunc Get(key string) string { return key }\n\nfunc Put(key string) {}\n", BumpLevelMinor, "1.3.0-alpha"},
+		{"Changed signature", "package client\n\nfunc Get(key string) (string, error) { return key, nil }\n", BumpLevelMajor, "2.0.0-alpha"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := testRepoAPIHistory(t, test.src)
+
+			_, components, err := CalculateWithComponents(Options{
+				Repository: repo,
+				Commitish:  plumbing.Revision("HEAD"),
+				APIBump:    true,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, components.APIReport)
+			require.Equal(t, test.bump, components.APIReport.Bump)
+			require.Equal(t, test.expected, components.Semver.String())
+			require.Contains(t, components.Overrides[0], test.bump+" bump for ")
+		})
+	}
+
+	t.Run("Incompatible changes reported", func(t *testing.T) {
+		repo := testRepoAPIHistory(t, "package client\n")
+
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			APIBump:    true,
+		})
+		require.NoError(t, err)
+		require.Len(t, components.APIReport.Incompatible(), 1)
+		require.Equal(t, "client.Get: removed", components.APIReport.Incompatible()[0].String())
+		require.Equal(t, "major bump for 1 incompatible API change", components.Overrides[0])
+	})
+
+	t.Run("Constraint limits the bump", func(t *testing.T) {
+		repo := testRepoAPIHistory(t, "package client\n")
+
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			APIBump:    true,
+			Constraint: "^1.2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0-alpha", components.Semver.String())
+		require.Contains(t, components.Overrides, "minor bump to stay within constraint ^1.2")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		repo := testRepoAPIHistory(t, "package client\n")
+
+		_, components, err := CalculateWithComponents(Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+		require.NoError(t, err)
+		require.Nil(t, components.APIReport)
+		require.Equal(t, "1.3.0-alpha", components.Semver.String())
+	})
+}
@@ -0,0 +1,209 @@
+package vers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchivalFileName is the file git fills in with commit metadata when an
+// archive is created, via the export-subst attribute
+const ArchivalFileName = ".git_archival.txt"
+
+// ArchivalTemplate is the unsubstituted content of the archival file
+const ArchivalTemplate = `node: $Format:%H$
+node-date: $Format:%cI$
+describe-name: $Format:%(describe:tags=true,match=*[0-9]*)$
+ref-names: $Format:%D$
+`
+
+// archivalAttribute marks the archival file for substitution in .gitattributes
+const archivalAttribute = ArchivalFileName + " export-subst"
+
+// ErrArchivalNotSubstituted is returned when the archival file still contains
+// its $Format:...$ placeholders, e.g. when read from a git checkout
+var ErrArchivalNotSubstituted = errors.New("archival file has not been substituted by git archive")
+
+// Archival contains the commit metadata recorded in a source archive
+type Archival struct {
+	// Node is the full commit hash
+	Node string
+
+	// NodeDate is the committer date of the commit
+	NodeDate time.Time
+
+	// DescribeName is the output of git describe, e.g. "v1.2.3-4-gabcdef1"
+	DescribeName string
+
+	// RefNames lists the refs pointing at the commit, e.g. "HEAD -> main, tag: v1.2.3"
+	RefNames string
+}
+
+var describeRe = regexp.MustCompile(`^(.+)-(\d+)-g[0-9a-f]+$`)
+
+// ReadArchival reads the archival file from the root of an extracted archive
+func ReadArchival(dir string) (*Archival, error) {
+	file, err := os.Open(filepath.Join(dir, ArchivalFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseArchival(file)
+}
+
+// ParseArchival parses the "key: value" lines of an archival file
+func ParseArchival(r io.Reader) (*Archival, error) {
+	archival := &Archival{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		if strings.Contains(value, "$Format:") {
+			return nil, ErrArchivalNotSubstituted
+		}
+
+		switch strings.TrimSpace(key) {
+		case "node":
+			archival.Node = value
+		case "node-date":
+			date, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("parsing node-date %q: %w", value, err)
+			}
+			archival.NodeDate = date
+		case "describe-name":
+			// Older git versions leave unsupported placeholders untouched
+			if !strings.Contains(value, "%(describe") {
+				archival.DescribeName = value
+			}
+		case "ref-names":
+			archival.RefNames = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading archival file: %w", err)
+	}
+
+	if len(archival.Node) < 8 || !isHex(archival.Node) {
+		return nil, fmt.Errorf("invalid node %q in archival file", archival.Node)
+	}
+
+	return archival, nil
+}
+
+// InitArchival writes the archival file into dir and marks it for
+// substitution in .gitattributes, leaving existing content in place
+func InitArchival(dir string) error {
+	archivalPath := filepath.Join(dir, ArchivalFileName)
+	if _, err := os.Stat(archivalPath); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(archivalPath, []byte(ArchivalTemplate), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", ArchivalFileName, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("checking %s: %w", ArchivalFileName, err)
+	}
+
+	attributesPath := filepath.Join(dir, ".gitattributes")
+	existing, err := os.ReadFile(attributesPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading .gitattributes: %w", err)
+	}
+
+	for _, line := range strings.Split(string(existing), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == ArchivalFileName && fields[1] == "export-subst" {
+			return nil
+		}
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += archivalAttribute + "\n"
+
+	if err := os.WriteFile(attributesPath, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("writing .gitattributes: %w", err)
+	}
+
+	return nil
+}
+
+func getArchivalComponents(opts Options) (*VersionComponents, error) {
+	archival := opts.Archival
+
+	baseTag, distance, isExact := archivalBaseTag(archival, opts.IsPreRelease, opts.TagFilter)
+
+	version, overrides, err := resolveVersion(baseTag, isExact, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	components := &VersionComponents{
+		Semver:      version,
+		Hash:        archival.Node,
+		ShortHash:   archival.Node[:8],
+		Timestamp:   archival.NodeDate,
+		IsExact:     isExact,
+		BaseTag:     baseTag,
+		Distance:    distance,
+		BuildNumber: opts.BuildNumber,
+		Overrides:   overrides,
+		Forced:      opts.Override != "",
+	}
+	components.Branch, components.PullRequest = branchContext(opts)
+
+	return components, nil
+}
+
+// archivalBaseTag mirrors determineBaseVersion using the describe output
+// and ref names recorded in the archive. Unlike a repository walk it cannot
+// look past a tag that is filtered out, so the describe match pattern should
+// agree with any tag filter in use. The distance is zero when unknown.
+func archivalBaseTag(archival *Archival, isPrerelease bool, tagFilter func(string) bool) (string, int, bool) {
+	// Tags pointing directly at the commit
+	for _, ref := range strings.Split(archival.RefNames, ",") {
+		tag, ok := strings.CutPrefix(strings.TrimSpace(ref), "tag: ")
+		if ok && tagAllowed("refs/tags/"+tag, isPrerelease, tagFilter) {
+			return tag, 0, true
+		}
+	}
+
+	if archival.DescribeName == "" {
+		return "", 0, false
+	}
+
+	tag, distance := archival.DescribeName, 0
+	if matches := describeRe.FindStringSubmatch(archival.DescribeName); matches != nil {
+		tag = matches[1]
+		distance, _ = strconv.Atoi(matches[2])
+	}
+
+	if !tagAllowed("refs/tags/"+tag, isPrerelease, tagFilter) {
+		return "", 0, false
+	}
+
+	return tag, distance, distance == 0
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package vers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+const testArchivalNode = "0123456789abcdef0123456789abcdef01234567"
+
+func testArchival(describe, refNames string) *Archival {
+	return &Archival{
+		Node:         testArchivalNode,
+		NodeDate:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		DescribeName: describe,
+		RefNames:     refNames,
+	}
+}
+
+func TestParseArchival(t *testing.T) {
+	t.Run("Substituted archival file", func(t *testing.T) {
+		content := "node: " + testArchivalNode + "\n" +
+			"node-date: 2024-01-02T03:04:05+00:00\n" +
+			"describe-name: v1.2.3-4-g0123456\n" +
+			"ref-names: HEAD -> main, origin/main\n"
+
+		archival, err := ParseArchival(strings.NewReader(content))
+		require.NoError(t, err)
+		require.Equal(t, testArchivalNode, archival.Node)
+		require.True(t, archival.NodeDate.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+		require.Equal(t, "v1.2.3-4-g0123456", archival.DescribeName)
+		require.Equal(t, "HEAD -> main, origin/main", archival.RefNames)
+	})
+
+	t.Run("Unsubstituted archival file", func(t *testing.T) {
+		_, err := ParseArchival(strings.NewReader(ArchivalTemplate))
+		require.ErrorIs(t, err, ErrArchivalNotSubstituted)
+	})
+
+	t.Run("Git without describe support", func(t *testing.T) {
+		content := "node: " + testArchivalNode + "\n" +
+			"node-date: 2024-01-02T03:04:05+00:00\n" +
+			"describe-name: %(describe:tags=true,match=*[0-9]*)\n" +
+			"ref-names: tag: v1.2.3\n"
+
+		archival, err := ParseArchival(strings.NewReader(content))
+		require.NoError(t, err)
+		require.Empty(t, archival.DescribeName)
+		require.Equal(t, "tag: v1.2.3", archival.RefNames)
+	})
+
+	t.Run("Missing node", func(t *testing.T) {
+		_, err := ParseArchival(strings.NewReader("node-date: 2024-01-02T03:04:05+00:00\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid node")
+	})
+}
+
+func TestCalculateFromArchival(t *testing.T) {
+	t.Run("Exact tag from describe", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3", version.SemVer)
+		require.Equal(t, "v1.2.3", version.Go)
+	})
+
+	t.Run("Exact tag from ref names", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("", "HEAD -> main, tag: sdk/v2.0.0")})
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0", version.SemVer)
+	})
+
+	t.Run("Commits past tag", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3-4-g0123456", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0-alpha.1704164645+01234567", version.SemVer)
+		require.Equal(t, "1.3.0a1704164645+g01234567", version.Python)
+	})
+
+	t.Run("No tags", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("", "HEAD -> main"), OmitCommitHash: true})
+		require.NoError(t, err)
+		require.Equal(t, "0.0.1-alpha.1704164645", version.SemVer)
+	})
+
+	t.Run("Tag rejected by pattern", func(t *testing.T) {
+		version, err := Calculate(Options{
+			Archival:       testArchival("v1.2.3", ""),
+			TagPattern:     "^sdk/",
+			OmitCommitHash: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "0.0.1-alpha.1704164645", version.SemVer)
+	})
+
+	t.Run("Matches repository version", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		repo, err = testRepoSingleCommitPastRelease(repo)
+		require.NoError(t, err)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+		commit, err := repo.CommitObject(head.Hash())
+		require.NoError(t, err)
+
+		fromRepo, err := Calculate(Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+		require.NoError(t, err)
+
+		fromArchival, err := Calculate(Options{Archival: &Archival{
+			Node:         head.Hash().String(),
+			NodeDate:     commit.Committer.When,
+			DescribeName: "v1.0.0-1-g" + head.Hash().String()[:7],
+		}})
+		require.NoError(t, err)
+		require.Equal(t, fromRepo, fromArchival)
+	})
+}
+
+func TestInitArchival(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.go text"), 0o644))
+
+	require.NoError(t, InitArchival(dir))
+	// Running twice must not duplicate the attribute
+	require.NoError(t, InitArchival(dir))
+
+	archival, err := os.ReadFile(filepath.Join(dir, ArchivalFileName))
+	require.NoError(t, err)
+	require.Equal(t, ArchivalTemplate, string(archival))
+
+	attributes, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	require.NoError(t, err)
+	require.Equal(t, "*.go text\n.git_archival.txt export-subst\n", string(attributes))
+
+	_, err = ReadArchival(dir)
+	require.ErrorIs(t, err, ErrArchivalNotSubstituted)
+}
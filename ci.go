@@ -0,0 +1,134 @@
+package vers
+
+import (
+	"strings"
+)
+
+// CI providers recognized by DetectCI
+const (
+	ProviderGitHubActions = "github-actions"
+	ProviderGitLabCI      = "gitlab-ci"
+	ProviderBuildkite     = "buildkite"
+)
+
+// CIContext is the branch, tag and pull request context reported by a CI
+// provider. CI systems usually check out a detached HEAD, so this is the only
+// reliable source of branch information, and tag builds may not fetch the tag.
+type CIContext struct {
+	// Provider is the CI system the context was read from
+	Provider string
+
+	// Branch is the branch being built, or the source branch of a pull request
+	Branch string
+
+	// Tag is the tag being built, if the build was triggered by a tag
+	Tag string
+
+	// PullRequest is the pull or merge request number, if any
+	PullRequest string
+
+	// Commit is the full hash of the commit being built
+	Commit string
+}
+
+// DetectCI reads CI provider environment variables, returning nil when not
+// running under a supported provider; getenv is usually os.Getenv
+func DetectCI(getenv func(string) string) *CIContext {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		return detectGitHubActions(getenv)
+	case getenv("GITLAB_CI") == "true":
+		return detectGitLabCI(getenv)
+	case getenv("BUILDKITE") == "true":
+		return detectBuildkite(getenv)
+	default:
+		return nil
+	}
+}
+
+func detectGitHubActions(getenv func(string) string) *CIContext {
+	ctx := &CIContext{
+		Provider: ProviderGitHubActions,
+		Commit:   getenv("GITHUB_SHA"),
+	}
+
+	ref := getenv("GITHUB_REF")
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		ctx.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		ctx.Tag = strings.TrimPrefix(ref, "refs/tags/")
+	case strings.HasPrefix(ref, "refs/pull/"):
+		// refs/pull/<number>/merge
+		number, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+		ctx.PullRequest = number
+		ctx.Branch = getenv("GITHUB_HEAD_REF")
+	}
+
+	return ctx
+}
+
+func detectGitLabCI(getenv func(string) string) *CIContext {
+	ctx := &CIContext{
+		Provider:    ProviderGitLabCI,
+		Commit:      getenv("CI_COMMIT_SHA"),
+		Tag:         getenv("CI_COMMIT_TAG"),
+		Branch:      getenv("CI_COMMIT_BRANCH"),
+		PullRequest: getenv("CI_MERGE_REQUEST_IID"),
+	}
+
+	if ctx.PullRequest != "" {
+		ctx.Branch = getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")
+	}
+
+	return ctx
+}
+
+func detectBuildkite(getenv func(string) string) *CIContext {
+	ctx := &CIContext{
+		Provider: ProviderBuildkite,
+		Tag:      getenv("BUILDKITE_TAG"),
+		Branch:   getenv("BUILDKITE_BRANCH"),
+	}
+
+	// Buildkite reports "false" for builds that aren't pull requests
+	if pr := getenv("BUILDKITE_PULL_REQUEST"); pr != "" && pr != "false" {
+		ctx.PullRequest = pr
+	}
+
+	// The commit may be a symbolic "HEAD" for builds triggered without a hash
+	if commit := getenv("BUILDKITE_COMMIT"); isHex(commit) && len(commit) == 40 {
+		ctx.Commit = commit
+	}
+
+	// Tag builds report the tag name as the branch
+	if ctx.Tag != "" && ctx.Branch == ctx.Tag {
+		ctx.Branch = ""
+	}
+
+	return ctx
+}
+
+// ciExactTag returns the tag reported by CI when it names the commit being
+// analyzed, so tag builds are recognized even if the tag wasn't fetched
+func ciExactTag(opts Options, hash string) (string, bool) {
+	ci := opts.CI
+	if ci == nil || ci.Tag == "" {
+		return "", false
+	}
+
+	// Without a commit hash only trust the tag for the checked out commit
+	if (ci.Commit != "" && ci.Commit != hash) || (ci.Commit == "" && opts.Commitish != "HEAD") {
+		return "", false
+	}
+
+	if !tagAllowed("refs/tags/"+ci.Tag, opts.IsPreRelease, opts.TagFilter) {
+		return "", false
+	}
+
+	if _, err := parseTagVersion(ci.Tag); err != nil {
+		return "", false
+	}
+
+	return ci.Tag, true
+}
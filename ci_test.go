@@ -0,0 +1,190 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+const testCICommit = "0123456789abcdef0123456789abcdef01234567"
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected *CIContext
+	}{
+		{
+			name:     "Not running in CI",
+			env:      map[string]string{"CI": "true"},
+			expected: nil,
+		},
+		{
+			name: "GitHub Actions branch",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true",
+				"GITHUB_REF":     "refs/heads/feature/login",
+				"GITHUB_SHA":     testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitHubActions, Branch: "feature/login", Commit: testCICommit},
+		},
+		{
+			name: "GitHub Actions tag",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true",
+				"GITHUB_REF":     "refs/tags/v1.2.3",
+				"GITHUB_SHA":     testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitHubActions, Tag: "v1.2.3", Commit: testCICommit},
+		},
+		{
+			name: "GitHub Actions pull request",
+			env: map[string]string{
+				"GITHUB_ACTIONS":  "true",
+				"GITHUB_REF":      "refs/pull/42/merge",
+				"GITHUB_HEAD_REF": "fix-bug",
+				"GITHUB_SHA":      testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitHubActions, Branch: "fix-bug", PullRequest: "42", Commit: testCICommit},
+		},
+		{
+			name: "GitLab CI branch",
+			env: map[string]string{
+				"GITLAB_CI":        "true",
+				"CI_COMMIT_BRANCH": "main",
+				"CI_COMMIT_SHA":    testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitLabCI, Branch: "main", Commit: testCICommit},
+		},
+		{
+			name: "GitLab CI tag",
+			env: map[string]string{
+				"GITLAB_CI":     "true",
+				"CI_COMMIT_TAG": "v2.0.0",
+				"CI_COMMIT_SHA": testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitLabCI, Tag: "v2.0.0", Commit: testCICommit},
+		},
+		{
+			name: "GitLab CI merge request",
+			env: map[string]string{
+				"GITLAB_CI":                           "true",
+				"CI_MERGE_REQUEST_IID":                "7",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature",
+				"CI_COMMIT_SHA":                       testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderGitLabCI, Branch: "feature", PullRequest: "7", Commit: testCICommit},
+		},
+		{
+			name: "Buildkite branch",
+			env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "main",
+				"BUILDKITE_PULL_REQUEST": "false",
+				"BUILDKITE_COMMIT":       testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderBuildkite, Branch: "main", Commit: testCICommit},
+		},
+		{
+			name: "Buildkite tag",
+			env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "v1.0.0",
+				"BUILDKITE_TAG":          "v1.0.0",
+				"BUILDKITE_PULL_REQUEST": "false",
+				"BUILDKITE_COMMIT":       "HEAD",
+			},
+			expected: &CIContext{Provider: ProviderBuildkite, Tag: "v1.0.0"},
+		},
+		{
+			name: "Buildkite pull request",
+			env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "feature",
+				"BUILDKITE_PULL_REQUEST": "15",
+				"BUILDKITE_COMMIT":       testCICommit,
+			},
+			expected: &CIContext{Provider: ProviderBuildkite, Branch: "feature", PullRequest: "15", Commit: testCICommit},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, DetectCI(testGetenv(test.env)))
+		})
+	}
+}
+
+func TestCalculateWithCI(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	head, err := testRepoSingleCommit(repo)
+	require.NoError(t, err)
+
+	t.Run("Unfetched tag is an exact release", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			CI:         &CIContext{Provider: ProviderGitHubActions, Tag: "v1.4.0", Commit: head.String()},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.4.0", versions.SemVer)
+		require.True(t, components.IsExact)
+		require.Equal(t, "v1.4.0", components.BaseTag)
+	})
+
+	t.Run("Tag for another commit is ignored", func(t *testing.T) {
+		versions, err := Calculate(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			CI:         &CIContext{Provider: ProviderGitHubActions, Tag: "v1.4.0", Commit: testCICommit},
+		})
+		require.NoError(t, err)
+		require.Contains(t, versions.SemVer, "0.0.1-alpha")
+	})
+
+	t.Run("Tag rejected by pattern", func(t *testing.T) {
+		versions, err := Calculate(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			TagPattern: "^sdk/",
+			CI:         &CIContext{Provider: ProviderGitLabCI, Tag: "v1.4.0", Commit: head.String()},
+		})
+		require.NoError(t, err)
+		require.Contains(t, versions.SemVer, "0.0.1-alpha")
+	})
+
+	t.Run("Pull request builds are dev pre-releases", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			CI:         &CIContext{Provider: ProviderBuildkite, Branch: "feature", PullRequest: "15"},
+		})
+		require.NoError(t, err)
+		require.Contains(t, versions.SemVer, "0.0.1-dev")
+		require.Equal(t, "feature", components.Branch)
+		require.Equal(t, "15", components.PullRequest)
+	})
+
+	t.Run("Explicit label wins over pull request", func(t *testing.T) {
+		versions, err := Calculate(Options{
+			Repository:      repo,
+			Commitish:       plumbing.Revision("HEAD"),
+			PrereleaseLabel: "beta",
+			CI:              &CIContext{Provider: ProviderBuildkite, Branch: "feature", PullRequest: "15"},
+		})
+		require.NoError(t, err)
+		require.Contains(t, versions.SemVer, "0.0.1-beta")
+	})
+
+	t.Run("Branch from repository outside CI", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "master", components.Branch)
+		require.Empty(t, components.PullRequest)
+	})
+}
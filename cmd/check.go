@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jaxxstorm/vers"
+)
+
+type CheckCmd struct {
+	versionFlags
+
+	AllowDowngrade    bool `help:"Allow versions lower than an existing tag"`
+	AllowDuplicate    bool `help:"Allow versions already tagged on another commit"`
+	AllowSkippedMajor bool `help:"Allow versions more than one major version above the latest tag"`
+	JSON              bool `short:"j" help:"Output the report as JSON"`
+}
+
+// checkReport is the JSON form of the vers check report
+type checkReport struct {
+	Version    string           `json:"version"`
+	Tags       int              `json:"tags"`
+	Violations []vers.Violation `json:"violations"`
+}
+
+func (c *CheckCmd) Run() error {
+	return c.check(os.Stdout)
+}
+
+// check reports the release policy violations of the calculated version,
+// failing if there are any
+func (c *CheckCmd) check(w io.Writer) error {
+	versions, components, err := c.calculate()
+	if err != nil {
+		return err
+	}
+	if components == nil || components.Hash == "" {
+		return fmt.Errorf("no git repository found; release policy needs the repository tags")
+	}
+
+	repoPath := c.Repo
+	if repoPath == "" {
+		repoPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+	}
+
+	repo, err := vers.OpenRepository(repoPath)
+	if err != nil {
+		return fmt.Errorf("opening repository: %w", err)
+	}
+
+	opts, err := c.toOptions()
+	if err != nil {
+		return err
+	}
+
+	// Policy applies to every tag of the build's own tag line, including
+	// pre-releases and tags outside the constraint, which can still be
+	// duplicated. The root module's line excludes other modules' tags.
+	tagOpts := vers.Options{
+		Repository:   repo,
+		IsPreRelease: true,
+		TagPattern:   opts.TagPattern,
+		ModuleDir:    opts.ModuleDir,
+	}
+	if tagOpts.TagPattern == "" && tagOpts.ModuleDir == "" {
+		tagOpts.TagFilter = func(tag string) bool {
+			return !strings.Contains(tag, "/")
+		}
+	}
+
+	tags, err := vers.ListTags(tagOpts)
+	if err != nil {
+		return err
+	}
+
+	violations, err := vers.CheckPolicy(*versions.Version, tags, vers.Policy{
+		AllowDowngrade:    c.AllowDowngrade,
+		AllowDuplicate:    c.AllowDuplicate,
+		AllowSkippedMajor: c.AllowSkippedMajor,
+		Constraint:        opts.Constraint,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.JSON {
+		report := checkReport{Version: versions.SemVer, Tags: len(tags), Violations: violations}
+		if report.Violations == nil {
+			report.Violations = []vers.Violation{}
+		}
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			return err
+		}
+	} else if len(violations) == 0 {
+		fmt.Fprintf(w, "%s passes release policy (%d tags checked)\n", versions.SemVer, len(tags))
+	} else {
+		fmt.Fprintf(w, "%s violates release policy:\n", versions.SemVer)
+		for _, violation := range violations {
+			fmt.Fprintf(w, "  %s: %s\n", violation.Rule, violation.Message)
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("release policy check failed with %d violation(s)", len(violations))
+	}
+
+	return nil
+}
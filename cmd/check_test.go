@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jaxxstorm/vers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCmd(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := testTaggedRepo(t)
+
+	t.Run("Passes", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&CheckCmd{versionFlags: versionFlags{Repo: dir}}).check(&out))
+		require.Equal(t, "1.2.3 passes release policy (1 tags checked)\n", out.String())
+	})
+
+	t.Run("Downgrade", func(t *testing.T) {
+		var out bytes.Buffer
+		err := (&CheckCmd{versionFlags: versionFlags{Repo: dir, VersionPrefix: "1.0.0"}}).check(&out)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "1 violation(s)")
+		require.Contains(t, out.String(), "violates release policy:\n  downgrade: ")
+	})
+
+	t.Run("Skipped major as JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		err := (&CheckCmd{versionFlags: versionFlags{Repo: dir, VersionPrefix: "3.0.0"}, JSON: true}).check(&out)
+		require.Error(t, err)
+
+		var report checkReport
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		require.Equal(t, 1, report.Tags)
+		require.Len(t, report.Violations, 1)
+		require.Equal(t, "skipped-major", report.Violations[0].Rule)
+		require.Equal(t, "v1.2.3", report.Violations[0].Tag)
+	})
+
+	t.Run("Allowed skipped major", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := &CheckCmd{versionFlags: versionFlags{Repo: dir, VersionPrefix: "3.0.0"}, AllowSkippedMajor: true}
+		require.NoError(t, cmd.check(&out))
+	})
+
+	t.Run("Not a repository", func(t *testing.T) {
+		err := (&CheckCmd{versionFlags: versionFlags{Repo: t.TempDir()}}).check(&bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no git repository found")
+	})
+}
+
+// testCommitTagged adds an empty commit to the repository in dir and tags it
+func testCommitTagged(t *testing.T, dir string, tags ...string) {
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit("Change", &git.CommitOptions{
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+	for _, tag := range tags {
+		_, err = repo.CreateTag(tag, hash, nil)
+		require.NoError(t, err)
+	}
+}
+
+func TestCheckCmdPreReleaseTags(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := testTaggedRepo(t)
+	testCommitTagged(t, dir, "v1.3.0-rc.1")
+	testCommitTagged(t, dir)
+
+	t.Run("Alpha after release candidate", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&CheckCmd{versionFlags: versionFlags{Repo: dir, NoCI: true}}).check(&out))
+		require.Contains(t, out.String(), "passes release policy (2 tags checked)")
+	})
+
+	t.Run("Release candidate taken on another commit", func(t *testing.T) {
+		t.Setenv(vers.EnvOverride, "1.3.0-rc.1")
+
+		var out bytes.Buffer
+		err := (&CheckCmd{versionFlags: versionFlags{Repo: dir, Constraint: "~1.2", NoCI: true}}).check(&out)
+		require.Error(t, err)
+		require.Contains(t, out.String(), "1.3.0-rc.1 violates release policy:\n  duplicate: ")
+	})
+}
+
+func TestCheckCmdModuleTags(t *testing.T) {
+	dir := testTaggedRepo(t)
+	testCommitTagged(t, dir, "sdk/v1.2.4")
+
+	for _, pattern := range []string{"", "^v"} {
+		t.Run("Tag pattern "+pattern, func(t *testing.T) {
+			t.Setenv(vers.EnvOverride, "1.2.4")
+
+			var out bytes.Buffer
+			require.NoError(t, (&CheckCmd{versionFlags: versionFlags{Repo: dir, TagPattern: pattern, NoCI: true}}).check(&out))
+			require.Equal(t, "1.2.4 passes release policy (1 tags checked)\n", out.String())
+		})
+	}
+}
+
+func TestCheckCmdMaintenanceBranch(t *testing.T) {
+	dir := testTaggedRepo(t)
+	testCommitTagged(t, dir, "v2.0.0")
+
+	var out bytes.Buffer
+	cmd := &CheckCmd{versionFlags: versionFlags{Repo: dir, Commitish: "v1.2.3", Constraint: "~1.2", VersionPrefix: "1.2.4", NoCI: true}}
+	require.NoError(t, cmd.check(&out))
+	require.Contains(t, out.String(), "passes release policy (2 tags checked)")
+}
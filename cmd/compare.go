@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jaxxstorm/vers"
+)
+
+// Exit codes of vers compare. Equal versions exit 0 so that
+// "vers compare A B" can guard a release step directly.
+const (
+	exitLess    = 11
+	exitGreater = 12
+)
+
+// exitCode is returned by commands whose result is reported through the
+// exit status rather than as an error
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+type CompareCmd struct {
+	A      string `arg:"" help:"First version"`
+	B      string `arg:"" help:"Second version"`
+	Format string `short:"f" default:"semver" enum:"semver,go,python,debian,rpm" help:"Ordering rules to apply (semver, go, python, debian, rpm)"`
+}
+
+type SortCmd struct {
+	Format      string `short:"f" default:"semver" enum:"semver,go,python,debian,rpm" help:"Ordering rules to apply (semver, go, python, debian, rpm)"`
+	Reverse     bool   `help:"Sort newest first"`
+	SkipInvalid bool   `help:"Drop lines that are not valid versions instead of failing"`
+}
+
+// Run prints lt, eq or gt and exits 11, 0 or 12 respectively
+func (c *CompareCmd) Run() error {
+	return c.compare(os.Stdout)
+}
+
+func (c *CompareCmd) compare(w io.Writer) error {
+	result, err := vers.CompareVersions(c.A, c.B, c.Format)
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case -1:
+		fmt.Fprintln(w, "lt")
+		return exitCode(exitLess)
+	case 1:
+		fmt.Fprintln(w, "gt")
+		return exitCode(exitGreater)
+	default:
+		fmt.Fprintln(w, "eq")
+		return nil
+	}
+}
+
+func (s *SortCmd) Run() error {
+	return s.sort(os.Stdin, os.Stdout)
+}
+
+// sort reads one version per line, ignoring blank lines, and writes them
+// in order
+func (s *SortCmd) sort(r io.Reader, w io.Writer) error {
+	var versions []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if s.SkipInvalid {
+			if _, err := vers.CompareVersions(line, line, s.Format); err != nil {
+				continue
+			}
+		}
+		versions = append(versions, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if err := vers.SortVersions(versions, s.Format); err != nil {
+		return err
+	}
+	if s.Reverse {
+		slices.Reverse(versions)
+	}
+
+	for _, version := range versions {
+		if _, err := fmt.Fprintln(w, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareCmd(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		format   string
+		output   string
+		expected error
+	}{
+		{"1.2.3", "1.2.4", "semver", "lt\n", exitCode(exitLess)},
+		{"v1.2.3", "1.2.3", "semver", "eq\n", nil},
+		{"1.2.3", "1.2.3rc1", "python", "gt\n", exitCode(exitGreater)},
+		{"1:1.0", "2.0", "debian", "gt\n", exitCode(exitGreater)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &CompareCmd{A: test.a, B: test.b, Format: test.format}
+			require.Equal(t, test.expected, cmd.compare(&out))
+			require.Equal(t, test.output, out.String())
+		})
+	}
+
+	for _, format := range []string{"semver", "debian", "rpm"} {
+		t.Run("Invalid "+format+" version", func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &CompareCmd{A: "1.2.3", B: "latest release", Format: format}
+			err := cmd.compare(&out)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "latest release")
+			require.Empty(t, out.String())
+		})
+	}
+}
+
+func TestSortCmd(t *testing.T) {
+	input := "v1.10.0\nv1.2.0\n\nlatest\nv1.2.0-rc.1\n"
+
+	t.Run("Skip invalid", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := &SortCmd{Format: "semver", SkipInvalid: true}
+		require.NoError(t, cmd.sort(strings.NewReader(input), &out))
+		require.Equal(t, "v1.2.0-rc.1\nv1.2.0\nv1.10.0\n", out.String())
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := &SortCmd{Format: "semver", SkipInvalid: true, Reverse: true}
+		require.NoError(t, cmd.sort(strings.NewReader(input), &out))
+		require.Equal(t, "v1.10.0\nv1.2.0\nv1.2.0-rc.1\n", out.String())
+	})
+
+	t.Run("Debian", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := &SortCmd{Format: "debian"}
+		require.NoError(t, cmd.sort(strings.NewReader("1.0-1\n1.0~rc1-1\n1:0.9-1\n"), &out))
+		require.Equal(t, "1.0~rc1-1\n1.0-1\n1:0.9-1\n", out.String())
+	})
+
+	t.Run("Skip invalid Debian versions", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := &SortCmd{Format: "debian", SkipInvalid: true}
+		require.NoError(t, cmd.sort(strings.NewReader("1.0-1\nlatest\n1.0~rc1-1\n"), &out))
+		require.Equal(t, "1.0~rc1-1\n1.0-1\n", out.String())
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		cmd := &SortCmd{Format: "semver"}
+		err := cmd.sort(strings.NewReader(input), &bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "latest")
+	})
+}
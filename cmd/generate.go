@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jaxxstorm/vers"
+)
+
+type GenerateCmd struct {
+	Go GenerateGoCmd `cmd:"" name:"go" help:"Generate a Go source file with version constants"`
+}
+
+type GenerateGoCmd struct {
+	versionFlags
+
+	Package string `help:"Package name for the generated file" default:"version"`
+	Out     string `help:"Output file, or - for stdout" default:"version_gen.go" type:"path"`
+	LDFlags string `name:"ldflags" help:"Print -X linker flags for the package at this import path instead of writing a file" placeholder:"IMPORT-PATH"`
+}
+
+func (g *GenerateGoCmd) Run() error {
+	versions, components, err := g.calculate()
+	if err != nil {
+		return err
+	}
+	if components == nil {
+		return fmt.Errorf("no git repository or %s found; refusing to generate a fallback version", vers.ArchivalFileName)
+	}
+
+	if g.LDFlags != "" {
+		fmt.Println(vers.GoLDFlags(g.LDFlags, versions, components))
+		return nil
+	}
+
+	source, err := vers.GenerateGo(g.Package, versions, components)
+	if err != nil {
+		return err
+	}
+
+	if g.Out == "-" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(g.Out, source, 0o644)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGoCmd(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := testTaggedRepo(t)
+	out := filepath.Join(dir, "version_gen.go")
+
+	cmd := &GenerateGoCmd{versionFlags: versionFlags{Repo: dir}, Package: "version", Out: out}
+	require.NoError(t, cmd.Run())
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "package version")
+	require.Contains(t, string(content), `Version = "1.2.3"`)
+}
+
+func TestGenerateGoCmdWithoutRepository(t *testing.T) {
+	cmd := &GenerateGoCmd{versionFlags: versionFlags{Repo: t.TempDir()}, Package: "version", Out: "-"}
+	err := cmd.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "refusing to generate a fallback version")
+}
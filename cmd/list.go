@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jaxxstorm/vers"
+)
+
+type ListCmd struct {
+	Repo         string `short:"r" help:"Repository path (default: current directory)"`
+	TagPattern   string `help:"Regex pattern to filter tags (e.g., '^sdk/')"`
+	IsPreRelease bool   `help:"Include beta and rc tags, as when calculating pre-release versions"`
+	Constraint   string `help:"Version range for base tags (e.g., '~1.4' or '>=1.0.0 <2.0.0')"`
+	ModuleDir    string `help:"Go module directory in a multi-module repository; selects its tags (e.g., sdk/v1.2.3)"`
+	Range        string `help:"Only list versions in a range (e.g., '>=1.2.0 <2.0.0')"`
+	JSON         bool   `short:"j" help:"Output as JSON"`
+}
+
+func (l *ListCmd) Run() error {
+	return l.list(os.Stdout)
+}
+
+func (l *ListCmd) list(w io.Writer) error {
+	repoPath := l.Repo
+	if repoPath == "" {
+		var err error
+		repoPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+	}
+
+	repo, err := vers.OpenRepository(repoPath)
+	if err != nil {
+		return fmt.Errorf("opening repository: %w", err)
+	}
+
+	// Tags are selected as for version calculation, including VERS_CONSTRAINT
+	opts, err := versionFlags{
+		TagPattern:   l.TagPattern,
+		IsPreRelease: l.IsPreRelease,
+		Constraint:   l.Constraint,
+		ModuleDir:    l.ModuleDir,
+	}.toOptions()
+	if err != nil {
+		return err
+	}
+	opts.Repository = repo
+
+	tags, err := vers.ListTags(opts)
+	if err != nil {
+		return err
+	}
+
+	if l.Range != "" {
+		tags, err = vers.FilterTags(tags, l.Range)
+		if err != nil {
+			return err
+		}
+	}
+
+	if l.JSON {
+		if tags == nil {
+			tags = []vers.Tag{}
+		}
+		return json.NewEncoder(w).Encode(tags)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tVERSION\tCOMMIT\tDATE\tTYPE\tPRERELEASE")
+	for _, tag := range tags {
+		kind := "lightweight"
+		if tag.Annotated {
+			kind = "annotated"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			tag.Name, tag.Version, tag.Commit[:8], tag.Date.UTC().Format("2006-01-02"), kind, strconv.FormatBool(tag.Prerelease))
+	}
+
+	return tw.Flush()
+}
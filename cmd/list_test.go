@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/jaxxstorm/vers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCmd(t *testing.T) {
+	dir := testTaggedRepo(t)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.2.3-alpha.1", head.Hash(), nil)
+	require.NoError(t, err)
+
+	t.Run("Table", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&ListCmd{Repo: dir}).list(&out))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Equal(t, []string{"TAG", "VERSION", "COMMIT", "DATE", "TYPE", "PRERELEASE"}, strings.Fields(lines[0]))
+		require.Equal(t, "v1.2.3-alpha.1", strings.Fields(lines[1])[0])
+		require.Equal(t, "true", strings.Fields(lines[1])[5])
+		require.Equal(t, []string{"v1.2.3", "1.2.3", head.Hash().String()[:8]}, strings.Fields(lines[2])[:3])
+		require.Equal(t, "lightweight", strings.Fields(lines[2])[4])
+	})
+
+	t.Run("JSON with range", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&ListCmd{Repo: dir, Range: ">=1.2.3", JSON: true}).list(&out))
+
+		var tags []vers.Tag
+		require.NoError(t, json.Unmarshal(out.Bytes(), &tags))
+		require.Len(t, tags, 1)
+		require.Equal(t, "v1.2.3", tags[0].Name)
+		require.Equal(t, "1.2.3", tags[0].Version.String())
+		require.Equal(t, head.Hash().String(), tags[0].Commit)
+	})
+
+	t.Run("Empty JSON list", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&ListCmd{Repo: dir, Range: ">=2.0.0", JSON: true}).list(&out))
+		require.Equal(t, "[]\n", out.String())
+	})
+
+	t.Run("Constraint from environment", func(t *testing.T) {
+		t.Setenv(vers.EnvConstraint, "~1.1")
+
+		var out bytes.Buffer
+		require.NoError(t, (&ListCmd{Repo: dir, JSON: true}).list(&out))
+		require.Equal(t, "[]\n", out.String())
+
+		out.Reset()
+		require.NoError(t, (&ListCmd{Repo: dir, Constraint: "~1.2", JSON: true}).list(&out))
+		var tags []vers.Tag
+		require.NoError(t, json.Unmarshal(out.Bytes(), &tags))
+		require.Len(t, tags, 2)
+		require.Equal(t, "v1.2.3", tags[1].Name)
+	})
+
+	t.Run("Module tags", func(t *testing.T) {
+		_, err := repo.CreateTag("sdk/v0.1.0", head.Hash(), nil)
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, (&ListCmd{Repo: dir, ModuleDir: "sdk", JSON: true}).list(&out))
+		var tags []vers.Tag
+		require.NoError(t, json.Unmarshal(out.Bytes(), &tags))
+		require.Len(t, tags, 1)
+		require.Equal(t, "sdk/v0.1.0", tags[0].Name)
+	})
+
+	t.Run("Not a repository", func(t *testing.T) {
+		err := (&ListCmd{Repo: t.TempDir()}).list(&bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "opening repository")
+	})
+}
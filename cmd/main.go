@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/go-git/go-git/v5"
@@ -17,21 +20,63 @@ import (
 var Version = "dev"
 
 type CLI struct {
-	Commitish      string `arg:"" optional:"" help:"Git commitish to analyze or version string to convert (default: HEAD)"`
-	Language       string `short:"l" default:"generic" enum:"generic,semver,python,javascript,js,node,dotnet,csharp,go,golang" help:"Output format"`
-	Repo           string `short:"r" help:"Repository path (default: current directory)"`
-	VersionPrefix  string `help:"Version prefix override (e.g., '3.0.0')"`
-	OmitCommitHash bool   `short:"o" help:"Omit commit hash from version"`
-	IsPreRelease   bool   `help:"Mark as pre-release version"`
-	TagPattern     string `help:"Regex pattern to filter tags (e.g., '^sdk/')"`
-	JSON           bool   `short:"j" help:"Output as JSON"`
-	ShowVersion    bool   `help:"Show version information" name:"version"`
+	Commitish    string `arg:"" optional:"" help:"Git commitish to analyze or version string to convert (default: HEAD)"`
+	versionFlags `embed:""`
+
+	Language          string `short:"l" default:"generic" enum:"generic,semver,python,javascript,js,node,dotnet,csharp,go,golang,maven,gradle,java,debian,deb,rpm,docker,oci,dotnet-assembly,dotnet-file,dotnet-informational,nuget-v1,android,apple,apple-build,npm,rubygems,gem,ruby,cargo,rust,helm,helm-app" help:"Output format"`
+	From              string `default:"auto" enum:"auto,semver,python,debian,rpm,go" help:"Format of a version string to convert (default: detect PEP 440, Debian and Go pseudo-versions)"`
+	JSON              bool   `short:"j" help:"Output as JSON"`
+	Format            string `short:"f" default:"text" enum:"text,json,github-actions,env,dotenv,shell,make" help:"Output format (text, json, github-actions, env, dotenv, shell, make)"`
+	GitHubEnv         bool   `name:"github-env" help:"With --format github-actions, also export VERS_* variables via GITHUB_ENV"`
+	EnvPrefix         string `default:"VERS_" help:"Variable name prefix for env, dotenv, shell and make formats"`
+	DockerTags        bool   `name:"docker-tags" help:"Print the container image tags to publish, one per line (1.2.3, 1.2, 1 and latest for releases)"`
+	DockerBranchTag   bool   `name:"docker-branch-tag" help:"With --docker-tags, also tag pre-release images with the branch name"`
+	VersionCodeLayout string `default:"MMmmppsnn" help:"Digit layout of Android versionCode and Apple CFBundleVersion: M major, m minor, p patch, s pre-release stage, n pre-release number"`
+	Template          string `short:"t" help:"Go text/template to render instead of a version string (e.g., 'myapp_{{.SemVer}}_linux')"`
+	Explain           bool   `help:"Explain how the version was derived (written to stderr)"`
+	ShowVersion       bool   `help:"Show version information" name:"version"`
+}
+
+type App struct {
+	Calculate    CLI             `cmd:"" default:"withargs" help:"Calculate a version from Git state or convert a version string (default)"`
+	InitArchival InitArchivalCmd `cmd:"" name:"init-archival" help:"Set up .git_archival.txt so source archives carry version metadata"`
+	Write        WriteCmd        `cmd:"" help:"Write the calculated version into project manifests"`
+	Generate     GenerateCmd     `cmd:"" help:"Generate source code embedding the calculated version"`
+	Compare      CompareCmd      `cmd:"" help:"Compare two versions, exiting 0 if equal, 11 if the first is lower and 12 if it is higher"`
+	Sort         SortCmd         `cmd:"" help:"Sort versions read from stdin, one per line"`
+	List         ListCmd         `cmd:"" help:"List the tags vers uses as release history, sorted by version"`
+	Check        CheckCmd        `cmd:"" help:"Check the calculated version against release policy, failing on downgrades, duplicates and skipped majors"`
+}
+
+// versionFlags are the calculation options shared by subcommands that
+// compute a version from the repository
+type versionFlags struct {
+	Repo                string `short:"r" help:"Repository path (default: current directory)"`
+	Commitish           string `short:"c" help:"Git commitish to analyze (default: HEAD)"`
+	VersionPrefix       string `help:"Version prefix override (e.g., '3.0.0')"`
+	OmitCommitHash      bool   `short:"o" help:"Omit commit hash from version"`
+	IsPreRelease        bool   `help:"Mark as pre-release version"`
+	TagPattern          string `help:"Regex pattern to filter tags (e.g., '^sdk/')"`
+	Constraint          string `help:"Version range for base tags (e.g., '~1.4' or '>=1.0.0 <2.0.0')"`
+	APIBump             bool   `name:"api-bump" help:"Choose the major, minor or patch increment from exported Go API changes since the base tag"`
+	ModuleDir           string `help:"Go module directory in a multi-module repository; selects its tags (e.g., sdk/v1.2.3) and checks its go.mod"`
+	StrictGoModule      bool   `name:"strict-go-module" help:"Fail instead of warning when the go.mod module path doesn't match the major version"`
+	NoCI                bool   `name:"no-ci" help:"Ignore branch and tag context from CI provider environment variables"`
+	MavenSnapshot       string `default:"snapshot" enum:"snapshot,timestamp" help:"Maven format for untagged builds: 1.3.0-SNAPSHOT or unique timestamped snapshots"`
+	PackageEpoch        string `help:"Epoch prepended to Debian versions (e.g., '1' for 1:1.2.3)"`
+	PackageRevision     string `help:"Debian revision and RPM Release (default RPM Release: 1)"`
+	FileVersionRevision string `default:"distance" enum:"distance,build-number,zero" help:"Fourth part of the .NET FileVersion: commits since the tag, the build number, or zero"`
+	HelmBuildMetadata   string `default:"keep" enum:"keep,strip" help:"Build metadata in Helm chart versions: keep it, or strip it for OCI registries that reject '+'"`
+}
+
+type InitArchivalCmd struct {
+	Dir string `arg:"" optional:"" help:"Repository root to write the files into (default: current directory)"`
 }
 
 func main() {
-	var cli CLI
+	var app App
 
-	kong.Parse(&cli,
+	ctx := kong.Parse(&app,
 		kong.Name("vers"),
 		kong.Description("Calculate semantic versions from Git repository state or convert version strings"),
 		kong.UsageOnError(),
@@ -43,7 +88,11 @@ func main() {
 		},
 	)
 
-	err := cli.Run()
+	err := ctx.Run()
+	var code exitCode
+	if errors.As(err, &code) {
+		os.Exit(int(code))
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -57,7 +106,7 @@ func (c *CLI) Run() error {
 	}
 
 	// Check if the input looks like a version string to convert
-	if c.Commitish != "" && isVersionString(c.Commitish) {
+	if c.Commitish != "" && (isVersionString(c.Commitish) || c.From != "auto" && c.From != "") {
 		return c.convertVersion()
 	}
 
@@ -71,78 +120,315 @@ func (c *CLI) showVersion() error {
 }
 
 func (c *CLI) convertVersion() error {
-	versions, err := vers.CalculateFromString(c.Commitish)
+	conversion, err := vers.ConvertString(c.Commitish, c.From)
 	if err != nil {
 		return fmt.Errorf("converting version: %w", err)
 	}
+	versions := conversion.Versions
+
+	for _, lost := range conversion.Lossy {
+		fmt.Fprintf(os.Stderr, "Warning: lossy conversion from %s: %s\n", conversion.Source, lost)
+	}
+
+	return c.printVersions(versions, nil)
+}
+
+func (c *CLI) calculateVersion() error {
+	versions, components, err := c.flags().calculate()
+	if err != nil {
+		return err
+	}
+
+	if c.Explain && components != nil {
+		explainVersion(os.Stderr, components)
+	}
+
+	return c.printVersions(versions, components)
+}
+
+// flags returns the calculation options given on the command line, where
+// the commitish may also be given as the argument
+func (c *CLI) flags() versionFlags {
+	flags := c.versionFlags
+	if c.Commitish != "" {
+		flags.Commitish = c.Commitish
+	}
+	return flags
+}
+
+// printVersions writes the versions in the requested output format.
+// Components are nil for converted and fallback versions.
+func (c *CLI) printVersions(versions *vers.LanguageVersions, components *vers.VersionComponents) error {
+	if c.Template != "" {
+		output, err := vers.RenderTemplate(c.Template, versions, components)
+		if err != nil {
+			return err
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	if c.DockerTags {
+		tags, err := vers.DockerTags(versions, components, c.DockerBranchTag)
+		if err != nil {
+			return err
+		}
+		if c.JSON || c.Format == "json" {
+			return json.NewEncoder(os.Stdout).Encode(tags)
+		}
+		fmt.Println(strings.Join(tags, "\n"))
+		return nil
+	}
 
-	if c.JSON {
+	if c.JSON || c.Format == "json" {
 		return json.NewEncoder(os.Stdout).Encode(versions)
 	}
 
+	switch c.Format {
+	case vers.EnvStyleEnv, vers.EnvStyleDotenv, vers.EnvStyleShell, vers.EnvStyleMake:
+		return vers.WriteEnvironment(os.Stdout, vers.Outputs(versions, components), c.EnvPrefix, c.Format)
+	case "github-actions":
+		if err := writeGitHubActions(vers.Outputs(versions, components), c.GitHubEnv); err != nil {
+			return err
+		}
+	}
+
+	switch c.Language {
+	case "android", "apple", "apple-build":
+		return c.printMobileVersion(versions, components)
+	}
+
 	output := getVersionOutput(versions, c.Language)
+	if output == "" {
+		return fmt.Errorf("%s has no %s version", versions.SemVer, c.Language)
+	}
 	fmt.Println(output)
 
 	return nil
 }
 
-func (c *CLI) calculateVersion() error {
+// printMobileVersion prints the Android versionCode, Apple
+// CFBundleShortVersionString or Apple CFBundleVersion
+func (c *CLI) printMobileVersion(versions *vers.LanguageVersions, components *vers.VersionComponents) error {
+	mobile, err := vers.NewMobileVersions(versions, components, c.VersionCodeLayout)
+	if err != nil {
+		return err
+	}
+
+	switch c.Language {
+	case "android":
+		fmt.Println(mobile.AndroidVersionCode)
+	case "apple":
+		fmt.Println(mobile.AppleShortVersion)
+	default:
+		fmt.Println(mobile.AppleBundleVersion)
+	}
+
+	return nil
+}
+
+// writeGitHubActions appends outputs to the GITHUB_OUTPUT file and, if
+// requested, exports them as VERS_* variables through the GITHUB_ENV file
+func writeGitHubActions(outputs []vers.Output, exportEnv bool) error {
+	if err := appendGitHubFile("GITHUB_OUTPUT", outputs); err != nil {
+		return err
+	}
+
+	if !exportEnv {
+		return nil
+	}
+
+	envOutputs := make([]vers.Output, len(outputs))
+	for i, output := range outputs {
+		envOutputs[i] = vers.Output{Name: vers.EnvName("VERS_", output.Name), Value: output.Value}
+	}
+
+	return appendGitHubFile("GITHUB_ENV", envOutputs)
+}
+
+func appendGitHubFile(variable string, outputs []vers.Output) error {
+	path := os.Getenv(variable)
+	if path == "" {
+		return fmt.Errorf("%s is not set; --format github-actions must run inside a GitHub Actions step", variable)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", variable, err)
+	}
+	defer file.Close()
+
+	if err := vers.WriteGitHubOutputs(file, outputs); err != nil {
+		return fmt.Errorf("writing %s: %w", variable, err)
+	}
+
+	return nil
+}
+
+// explainVersion writes a human-readable account of how the version was derived
+func explainVersion(w io.Writer, components *vers.VersionComponents) {
+	if components.ShortHash == "" {
+		fmt.Fprintf(w, "Commit:    none\n")
+	} else {
+		fmt.Fprintf(w, "Commit:    %s (%s)\n", components.ShortHash, components.Timestamp.UTC().Format(time.RFC3339))
+	}
+
+	switch {
+	case components.BaseTag == "":
+		fmt.Fprintf(w, "Base tag:  none\n")
+	case components.IsExact:
+		fmt.Fprintf(w, "Base tag:  %s (exact match)\n", components.BaseTag)
+	default:
+		fmt.Fprintf(w, "Base tag:  %s\n", components.BaseTag)
+	}
+
+	switch {
+	case components.PullRequest != "":
+		fmt.Fprintf(w, "Branch:    %s (pull request %s)\n", components.Branch, components.PullRequest)
+	case components.Branch != "":
+		fmt.Fprintf(w, "Branch:    %s\n", components.Branch)
+	}
+
+	fmt.Fprintf(w, "Dirty:     %t\n", components.Dirty)
+
+	if len(components.Overrides) == 0 {
+		fmt.Fprintf(w, "Overrides: none\n")
+	}
+	for _, override := range components.Overrides {
+		fmt.Fprintf(w, "Override:  %s\n", override)
+	}
+
+	if components.APIReport != nil {
+		for _, change := range components.APIReport.Incompatible() {
+			fmt.Fprintf(w, "Breaking:  %s\n", change)
+		}
+	}
+}
+
+// calculate computes versions from the repository, or from archival
+// metadata when there is no repository. Outside either, or in a repository
+// without commits, it returns the forced version if there is one, otherwise
+// the fallback version with nil components.
+func (f versionFlags) calculate() (versions *vers.LanguageVersions, components *vers.VersionComponents, err error) {
+	// Warnings are printed whichever source the components came from
+	defer func() {
+		if err == nil && components != nil {
+			for _, warning := range components.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+		}
+	}()
+
 	commitish := "HEAD"
-	if c.Commitish != "" {
-		commitish = c.Commitish
+	if f.Commitish != "" {
+		commitish = f.Commitish
 	}
 
-	repoPath := c.Repo
+	repoPath := f.Repo
 	if repoPath == "" {
 		var err error
 		repoPath, err = os.Getwd()
 		if err != nil {
-			return fmt.Errorf("getting current directory: %w", err)
+			return nil, nil, fmt.Errorf("getting current directory: %w", err)
 		}
 	}
 
+	opts, err := f.toOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Try to open repository, but handle gracefully if it's not a git repo
 	repo, err := vers.OpenRepository(repoPath)
 	if err != nil {
-		// If we can't open the repository, generate a fallback version
-		versions := vers.GenerateFallbackVersion()
+		// Source archives carry their commit metadata in an archival file
+		if archival, err := vers.ReadArchival(repoPath); err == nil {
+			opts.Archival = archival
+			versions, components, err := vers.CalculateWithComponents(opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("calculating version from %s: %w", vers.ArchivalFileName, err)
+			}
+			return versions, components, nil
+		}
 
-		if c.JSON {
-			return json.NewEncoder(os.Stdout).Encode(versions)
+		// A forced version needs no repository
+		if opts.Override != "" {
+			versions, components, err := vers.CalculateWithComponents(opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("calculating version: %w", err)
+			}
+			return versions, components, nil
 		}
 
-		output := getVersionOutput(versions, c.Language)
-		fmt.Println(output)
-		return nil
+		// If we can't open the repository, generate a fallback version
+		return vers.GenerateFallbackVersion(), nil, nil
 	}
 
 	// Validate commitish against actual git repository
-	if c.Commitish != "" && !isValidCommitishInRepo(repo, c.Commitish) {
-		return fmt.Errorf("'%s' does not exist in this git repository (not a valid branch, tag, or commit)", c.Commitish)
+	if f.Commitish != "" && !isValidCommitishInRepo(repo, f.Commitish) {
+		return nil, nil, fmt.Errorf("'%s' does not exist in this git repository (not a valid branch, tag, or commit)", f.Commitish)
 	}
 
+	opts.Repository = repo
+	opts.Commitish = plumbing.Revision(commitish)
+
+	versions, components, err = vers.CalculateWithComponents(opts)
+	if errors.Is(err, vers.ErrNoCommits) {
+		// A repository without commits has nothing to version yet
+		return vers.GenerateFallbackVersion(), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("calculating version: %w", err)
+	}
+
+	return versions, components, nil
+}
+
+// toOptions builds the calculation options shared by repositories and
+// archives, including any VERS_* environment overrides
+func (f versionFlags) toOptions() (vers.Options, error) {
 	opts := vers.Options{
-		Repository:     repo,
-		Commitish:      plumbing.Revision(commitish),
-		OmitCommitHash: c.OmitCommitHash,
-		ReleasePrefix:  c.VersionPrefix,
-		IsPreRelease:   c.IsPreRelease,
-		TagPattern:     c.TagPattern,
+		OmitCommitHash:      f.OmitCommitHash,
+		ReleasePrefix:       f.VersionPrefix,
+		IsPreRelease:        f.IsPreRelease,
+		TagPattern:          f.TagPattern,
+		Constraint:          f.Constraint,
+		APIBump:             f.APIBump,
+		ModuleDir:           f.ModuleDir,
+		StrictGoModule:      f.StrictGoModule,
+		MavenSnapshot:       f.MavenSnapshot,
+		PackageEpoch:        f.PackageEpoch,
+		PackageRevision:     f.PackageRevision,
+		FileVersionRevision: f.FileVersionRevision,
+		HelmBuildMetadata:   f.HelmBuildMetadata,
 	}
 
-	versions, err := vers.Calculate(opts)
-	if err != nil {
-		// If calculation fails (e.g., no git history), use fallback
-		versions = vers.GenerateFallbackVersion()
+	if err := vers.ApplyEnvironment(&opts, os.Getenv); err != nil {
+		return opts, fmt.Errorf("reading environment overrides: %w", err)
 	}
 
-	if c.JSON {
-		return json.NewEncoder(os.Stdout).Encode(versions)
+	if !f.NoCI {
+		opts.CI = vers.DetectCI(os.Getenv)
 	}
 
-	output := getVersionOutput(versions, c.Language)
-	fmt.Println(output)
+	return opts, nil
+}
+
+func (i *InitArchivalCmd) Run() error {
+	dir := i.Dir
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+	}
 
+	if err := vers.InitArchival(dir); err != nil {
+		return fmt.Errorf("initializing archival metadata: %w", err)
+	}
+
+	fmt.Printf("Wrote %s and .gitattributes entry; commit both files to enable archive versioning\n", vers.ArchivalFileName)
 	return nil
 }
 
@@ -155,6 +441,10 @@ func isVersionString(input string) bool {
 
 	// Check if it looks like a semantic version (with or without 'v' prefix)
 	trimmed := strings.TrimPrefix(input, "v")
+	// Debian epochs and PEP 440 epochs precede the version
+	if i := strings.IndexAny(trimmed, ":!"); i > 0 && isNumeric(trimmed[:i]) {
+		trimmed = trimmed[i+1:]
+	}
 	if trimmed == input && strings.HasPrefix(input, "v") {
 		// If trimming 'v' didn't change anything but input starts with 'v',
 		// it might be something like "version" which isn't a version string
@@ -177,20 +467,24 @@ func isVersionString(input string) bool {
 		return false
 	}
 
-	// Second part must be numeric
+	// Second part must be numeric, unless it is a two-part PEP 440 version
+	// with a pre-, post- or dev-release marker such as 1.2a1 or 2.0.dev3
+	if parsed, err := vers.ParsePEP440(trimmed); err == nil && parsed.HasMarker() {
+		return true
+	}
 	if len(parts[1]) == 0 || !isNumeric(parts[1]) {
 		return false
 	}
 
 	// If there's a third part, it should be numeric or contain pre-release info
 	if len(parts) >= 3 && len(parts[2]) > 0 {
-		// Allow formats like "1.2.3", "1.2.3-alpha", "1.2.3+build"
+		// Allow formats like "1.2.3", "1.2.3-alpha", "1.2.3+build", and the
+		// PEP 440 and Debian pre-releases "1.2.3a1" and "1.2.3~rc1"
 		patchPart := parts[2]
-		// Split on '-' or '+' to separate patch from pre-release/build metadata
-		if idx := strings.IndexAny(patchPart, "-+"); idx >= 0 {
+		if idx := strings.IndexFunc(patchPart, func(r rune) bool { return r < '0' || r > '9' }); idx >= 0 {
 			patchPart = patchPart[:idx]
 		}
-		if !isNumeric(patchPart) {
+		if patchPart == "" {
 			return false
 		}
 	}
@@ -227,9 +521,14 @@ func isGitReference(input string) bool {
 		return true
 	}
 
-	// Check for relative commit references like "HEAD~1", "HEAD^", "HEAD~10"
-	if strings.Contains(input, "~") || strings.Contains(input, "^") {
-		return true
+	// Check for relative commit references like "HEAD~1", "HEAD^", "HEAD~10",
+	// where "~" and "^" are followed only by digits, unlike the Debian and
+	// RPM versions "1.2.3~rc1" and "1.2.3^git1"
+	if i := strings.IndexAny(input, "~^"); i >= 0 {
+		rest := strings.TrimLeft(input[i:], "~^0123456789")
+		if rest == "" {
+			return true
+		}
 	}
 
 	return false
@@ -369,6 +668,32 @@ func getVersionOutput(versions *vers.LanguageVersions, language string) string {
 		return versions.DotNet
 	case "go", "golang":
 		return versions.Go
+	case "maven", "gradle", "java":
+		return versions.Maven
+	case "debian", "deb":
+		return versions.Debian
+	case "rpm":
+		return versions.RPM
+	case "docker", "oci":
+		return versions.Docker
+	case "npm":
+		return versions.NPM
+	case "rubygems", "gem", "ruby":
+		return versions.RubyGems
+	case "cargo", "rust":
+		return versions.Cargo
+	case "helm":
+		return versions.Helm
+	case "helm-app":
+		return versions.HelmAppVersion
+	case "dotnet-assembly":
+		return versions.DotNetAssembly
+	case "dotnet-file":
+		return versions.DotNetFile
+	case "dotnet-informational":
+		return versions.DotNetInformational
+	case "nuget-v1":
+		return versions.NuGetV1
 	default:
 		return versions.SemVer
 	}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jaxxstorm/vers"
 	"github.com/stretchr/testify/require"
 )
@@ -28,6 +33,17 @@ func TestIsVersionString(t *testing.T) {
 		{"1.2", true}, // x.y format is valid
 		{"v", false},
 		{"1", false},
+		{"1.2.3a1", true},
+		{"1.2a1", true},
+		{"2.0rc1", true},
+		{"2.0.dev3", true},
+		{"1.2.post1", true},
+		{"1.2.3~rc1", true},
+		{"1.2.3^git1", true},
+		{"1.2.3.4rc1", true},
+		{"1:1.2.3-1", true},
+		{"v0.0.0-20240101120000-abcdef123456", true},
+		{"1.2.x", false},
 	}
 
 	for _, test := range tests {
@@ -45,6 +61,7 @@ func TestGetVersionOutput(t *testing.T) {
 		JavaScript: "v1.2.3",
 		DotNet:     "1.2.3",
 		Go:         "v1.2.3",
+		Maven:      "1.2.3-SNAPSHOT",
 	}
 
 	tests := []struct {
@@ -62,6 +79,9 @@ func TestGetVersionOutput(t *testing.T) {
 		{"csharp", "1.2.3"},
 		{"go", "v1.2.3"},
 		{"golang", "v1.2.3"},
+		{"maven", "1.2.3-SNAPSHOT"},
+		{"gradle", "1.2.3-SNAPSHOT"},
+		{"java", "1.2.3-SNAPSHOT"},
 		{"unknown", "1.2.3"}, // Should default to SemVer
 	}
 
@@ -142,6 +162,28 @@ func TestCLIConvertVersion(t *testing.T) {
 	require.Equal(t, "1.2.3", outputStr)
 }
 
+func TestCLIConvertVersionFrom(t *testing.T) {
+	cli := &CLI{Commitish: "1!1.2.3rc1", From: "python", Language: "semver"}
+
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	er, ew, _ := os.Pipe()
+	os.Stdout, os.Stderr = w, ew
+
+	err := cli.convertVersion()
+	require.NoError(t, err)
+
+	w.Close()
+	ew.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	output, _ := ioutil.ReadAll(r)
+	warnings, _ := ioutil.ReadAll(er)
+
+	require.Equal(t, "1.2.3-rc.1", strings.TrimSpace(string(output)))
+	require.Contains(t, string(warnings), "lossy conversion from python: epoch 1 dropped")
+}
+
 func TestCLIConvertVersionJSON(t *testing.T) {
 	cli := &CLI{Commitish: "1.2.3", JSON: true}
 
@@ -175,7 +217,7 @@ func TestCLICalculateVersionNonGitRepo(t *testing.T) {
 	require.NoError(t, err)
 	defer os.RemoveAll(tmpDir)
 
-	cli := &CLI{Repo: tmpDir, Language: "generic"}
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, Language: "generic"}
 
 	// Capture stdout
 	oldStdout := os.Stdout
@@ -194,13 +236,65 @@ func TestCLICalculateVersionNonGitRepo(t *testing.T) {
 	require.Equal(t, "0.0.0-dev", outputStr)
 }
 
+func TestCLICalculateVersionNonGitRepoOverride(t *testing.T) {
+	t.Setenv(vers.EnvOverride, "1.2.3-rc+build.5")
+
+	cli := &CLI{versionFlags: versionFlags{Repo: t.TempDir()}, Language: "generic"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.calculateVersion()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "1.2.3-rc+build.5\n", string(output))
+}
+
+func TestCLICalculateVersionEmptyRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	_, err := git.PlainInit(tmpDir, false)
+	require.NoError(t, err)
+
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, Language: "generic"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = cli.calculateVersion()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "0.0.0-dev\n", string(output))
+}
+
+func TestCLICalculateVersionError(t *testing.T) {
+	dir := testTaggedRepo(t)
+
+	cli := &CLI{versionFlags: versionFlags{Repo: dir, TagPattern: "v[0-9"}, Language: "generic"}
+	err := cli.calculateVersion()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "calculating version")
+	require.Contains(t, err.Error(), "invalid tag pattern")
+}
+
 func TestCLICalculateVersionNonGitRepoJSON(t *testing.T) {
 	// Create a temporary non-git directory
 	tmpDir, err := ioutil.TempDir("", "non-git")
 	require.NoError(t, err)
 	defer os.RemoveAll(tmpDir)
 
-	cli := &CLI{Repo: tmpDir, JSON: true}
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, JSON: true}
 
 	// Capture stdout
 	oldStdout := os.Stdout
@@ -274,7 +368,7 @@ func TestCLIRun(t *testing.T) {
 		require.NoError(t, err)
 		defer os.RemoveAll(tmpDir)
 
-		cli := &CLI{Repo: tmpDir}
+		cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}}
 
 		// Capture stdout to avoid polluting test output
 		oldStdout := os.Stdout
@@ -291,3 +385,310 @@ func TestCLIRun(t *testing.T) {
 		require.Equal(t, "0.0.0-dev\n", string(output))
 	})
 }
+
+func TestCLICalculateVersionFromArchival(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "node: 0123456789abcdef0123456789abcdef01234567\n" +
+		"node-date: 2024-01-02T03:04:05+00:00\n" +
+		"describe-name: v1.2.3\n" +
+		"ref-names: HEAD -> main, tag: v1.2.3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, vers.ArchivalFileName), []byte(content), 0o644))
+
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, Language: "go"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.calculateVersion()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "v1.2.3\n", string(output))
+}
+
+func TestCLICalculateVersionDotNetFileOutOfRange(t *testing.T) {
+	t.Setenv(vers.EnvBuildNumber, "70000")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("BUILDKITE", "")
+
+	tmpDir := t.TempDir()
+	content := "node: 0123456789abcdef0123456789abcdef01234567\n" +
+		"node-date: 2024-01-02T03:04:05+00:00\n" +
+		"describe-name: v1.2.3-4-g0123456\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, vers.ArchivalFileName), []byte(content), 0o644))
+
+	flags := versionFlags{Repo: tmpDir, OmitCommitHash: true, FileVersionRevision: vers.FileVersionRevisionBuildNumber}
+
+	// Capture stdout and the warning on stderr
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	er, stderr, _ := os.Pipe()
+	os.Stderr = stderr
+
+	err := (&CLI{versionFlags: flags, Language: "generic"}).calculateVersion()
+	require.NoError(t, err)
+
+	err = (&CLI{versionFlags: flags, Language: "dotnet-file"}).calculateVersion()
+
+	w.Close()
+	stderr.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	require.Error(t, err)
+	require.Equal(t, "1.3.0-alpha.70000 has no dotnet-file version", err.Error())
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "1.3.0-alpha.70000\n", string(output))
+
+	warnings, _ := ioutil.ReadAll(er)
+	require.Equal(t, strings.Repeat("Warning: no .NET FileVersion for 1.3.0 revision 70000: parts can't exceed 65535\n", 2), string(warnings))
+}
+
+func TestInitArchivalCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Capture stdout to avoid polluting test output
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := (&InitArchivalCmd{Dir: tmpDir}).Run()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(tmpDir, vers.ArchivalFileName))
+	require.FileExists(t, filepath.Join(tmpDir, ".gitattributes"))
+}
+
+func TestCLICalculateVersionEnvironmentOverride(t *testing.T) {
+	t.Setenv(vers.EnvOverride, "4.5.6")
+
+	tmpDir := t.TempDir()
+	repo, err := git.PlainInit(tmpDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("content"), 0o644))
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, Language: "go"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = cli.calculateVersion()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "v4.5.6\n", string(output))
+}
+
+func TestCLICalculateVersionInvalidEnvironmentOverride(t *testing.T) {
+	t.Setenv(vers.EnvPrereleaseLabel, "nightly")
+
+	tmpDir := t.TempDir()
+	_, err := git.PlainInit(tmpDir, false)
+	require.NoError(t, err)
+
+	cli := &CLI{versionFlags: versionFlags{Repo: tmpDir}, Language: "generic"}
+	err = cli.calculateVersion()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), vers.EnvPrereleaseLabel)
+}
+
+func TestExplainVersion(t *testing.T) {
+	components := &vers.VersionComponents{
+		ShortHash: "abcdef12",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		BaseTag:   "v1.2.3",
+		Branch:    "main",
+		Overrides: []string{"version forced to 2.0.0"},
+		Forced:    true,
+	}
+
+	var buf bytes.Buffer
+	explainVersion(&buf, components)
+
+	require.Equal(t, "Commit:    abcdef12 (2024-01-02T03:04:05Z)\n"+
+		"Base tag:  v1.2.3\n"+
+		"Branch:    main\n"+
+		"Dirty:     false\n"+
+		"Override:  version forced to 2.0.0\n", buf.String())
+}
+
+func TestExplainVersionAPIReport(t *testing.T) {
+	components := &vers.VersionComponents{
+		ShortHash: "abcdef12",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		BaseTag:   "v1.2.3",
+		Overrides: []string{"major bump for 1 incompatible API change and 1 compatible API change"},
+		APIReport: &vers.APIReport{
+			Bump: vers.BumpLevelMajor,
+			Changes: []vers.APIChange{
+				{Package: "client", Object: "Get", Message: "removed"},
+				{Package: "client", Object: "Put", Message: "added", Compatible: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	explainVersion(&buf, components)
+
+	require.Equal(t, "Commit:    abcdef12 (2024-01-02T03:04:05Z)\n"+
+		"Base tag:  v1.2.3\n"+
+		"Dirty:     false\n"+
+		"Override:  major bump for 1 incompatible API change and 1 compatible API change\n"+
+		"Breaking:  client.Get: removed\n", buf.String())
+}
+
+func TestCLIGitHubActionsFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputPath := filepath.Join(tmpDir, "output")
+	envPath := filepath.Join(tmpDir, "env")
+	t.Setenv("GITHUB_OUTPUT", outputPath)
+	t.Setenv("GITHUB_ENV", envPath)
+
+	cli := &CLI{Commitish: "1.2.3-beta.1", Format: "github-actions", GitHubEnv: true}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.Run()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "1.2.3-beta.1\n", string(output))
+
+	outputs, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Contains(t, string(outputs), "python<<ghadelimiter_")
+	require.Contains(t, string(outputs), "\n1.2.3b1\n")
+
+	env, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	require.Contains(t, string(env), "VERS_SEMVER<<ghadelimiter_")
+}
+
+func TestCLIGitHubActionsFormatOutsideActions(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+
+	cli := &CLI{Commitish: "1.2.3", Format: "github-actions"}
+	err := cli.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GITHUB_OUTPUT is not set")
+}
+
+func TestCLIShellFormat(t *testing.T) {
+	cli := &CLI{Commitish: "1.2.3", Format: "shell", EnvPrefix: "APP_"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.Run()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.True(t, strings.HasPrefix(string(output), "export APP_SEMVER='1.2.3';\n"+
+		"export APP_PYTHON='1.2.3';\n"+
+		"export APP_JAVASCRIPT='v1.2.3';\n"+
+		"export APP_DOTNET='1.2.3';\n"+
+		"export APP_GO='v1.2.3';\n"), string(output))
+	require.Contains(t, string(output), "export APP_MAVEN='1.2.3';\n")
+	require.NotContains(t, string(output), "APP_MAJOR")
+}
+
+func TestCLITemplate(t *testing.T) {
+	cli := &CLI{Commitish: "1.2.3", Template: "myapp_{{.SemVer}}_{{.Major}}{{.Minor | pad 2}}"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.Run()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "myapp_1.2.3_102\n", string(output))
+}
+
+func TestCLIDockerTags(t *testing.T) {
+	cli := &CLI{Commitish: "1.2.3", DockerTags: true}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := cli.Run()
+	require.NoError(t, err)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := ioutil.ReadAll(r)
+	require.Equal(t, "1.2.3\n1.2\n1\nlatest\n", string(output))
+}
+
+func TestCLIMobileVersions(t *testing.T) {
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{"android", "10300302\n"},
+		{"apple", "1.3.0\n"},
+		{"apple-build", "10300302\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			cli := &CLI{Commitish: "1.3.0-rc.2", Language: test.language}
+
+			// Capture stdout
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := cli.Run()
+			require.NoError(t, err)
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			output, _ := ioutil.ReadAll(r)
+			require.Equal(t, test.expected, string(output))
+		})
+	}
+}
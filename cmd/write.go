@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jaxxstorm/vers"
+)
+
+type WriteCmd struct {
+	versionFlags
+
+	Files []string `arg:"" help:"Manifests to update (package.json, pyproject.toml, Cargo.toml, *.csproj, Chart.yaml)"`
+	Check bool     `help:"Fail if any manifest is out of date instead of writing"`
+}
+
+func (w *WriteCmd) Run() error {
+	versions, components, err := w.calculate()
+	if err != nil {
+		return err
+	}
+	if components == nil {
+		return fmt.Errorf("no git repository or %s found; refusing to write a fallback version", vers.ArchivalFileName)
+	}
+
+	var outdated []string
+	for _, file := range w.Files {
+		changed, err := vers.UpdateManifest(file, versions, w.Check)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case changed && w.Check:
+			outdated = append(outdated, file)
+		case changed:
+			fmt.Printf("Updated %s\n", file)
+		default:
+			fmt.Printf("%s is up to date\n", file)
+		}
+	}
+
+	if len(outdated) > 0 {
+		return fmt.Errorf("out of date: %s (run vers write to update)", strings.Join(outdated, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// testTaggedRepo creates a repository with a single commit tagged v1.2.3
+func testTaggedRepo(t *testing.T) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{\n  \"version\": \"0.0.0\"\n}\n"), 0o644))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	hash, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.2.3", hash, nil)
+	require.NoError(t, err)
+
+	return dir
+}
+
+func TestWriteCmd(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := testTaggedRepo(t)
+	manifest := filepath.Join(dir, "package.json")
+
+	// Capture stdout to avoid polluting test output
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		w.Close()
+		os.Stdout = oldStdout
+	}()
+
+	check := &WriteCmd{versionFlags: versionFlags{Repo: dir}, Files: []string{manifest}, Check: true}
+	err := check.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "out of date")
+
+	write := &WriteCmd{versionFlags: versionFlags{Repo: dir}, Files: []string{manifest}}
+	require.NoError(t, write.Run())
+
+	content, err := os.ReadFile(manifest)
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"version\": \"1.2.3\"\n}\n", string(content))
+}
+
+func TestWriteCmdWithoutRepository(t *testing.T) {
+	write := &WriteCmd{versionFlags: versionFlags{Repo: t.TempDir()}, Files: []string{"package.json"}}
+	err := write.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "refusing to write a fallback version")
+}
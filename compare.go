@@ -0,0 +1,73 @@
+package vers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CompareVersions compares two versions using the ordering rules of a
+// format: SourceSemVer (the default, also used for SourceGo), SourcePython
+// (PEP 440), SourceDebian (dpkg) or SourceRPM (rpmvercmp). It returns -1,
+// 0 or 1, or an error if either version is invalid in that format.
+func CompareVersions(a, b, format string) (int, error) {
+	switch format {
+	case "", SourceSemVer, SourceGo:
+		va, err := Parse(a)
+		if err != nil {
+			return 0, err
+		}
+		vb, err := Parse(b)
+		if err != nil {
+			return 0, err
+		}
+		return va.Compare(vb), nil
+	case SourcePython:
+		va, err := ParsePEP440(a)
+		if err != nil {
+			return 0, err
+		}
+		vb, err := ParsePEP440(b)
+		if err != nil {
+			return 0, err
+		}
+		return va.Compare(vb), nil
+	case SourceDebian:
+		if err := ValidateDebian(a); err != nil {
+			return 0, err
+		}
+		if err := ValidateDebian(b); err != nil {
+			return 0, err
+		}
+		return CompareDebian(a, b), nil
+	case SourceRPM:
+		if err := ValidateRPM(a); err != nil {
+			return 0, err
+		}
+		if err := ValidateRPM(b); err != nil {
+			return 0, err
+		}
+		return CompareRPM(a, b), nil
+	default:
+		return 0, fmt.Errorf("unknown version format %q (expected %s, %s, %s, %s or %s)",
+			format, SourceSemVer, SourceGo, SourcePython, SourceDebian, SourceRPM)
+	}
+}
+
+// SortVersions sorts versions in ascending order using the ordering rules
+// of a format (see CompareVersions). Versions that compare equal keep
+// their order. The slice is left unchanged if any version is invalid.
+func SortVersions(versions []string, format string) error {
+	// Comparing each version with itself validates it
+	for _, version := range versions {
+		if _, err := CompareVersions(version, version, format); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		c, _ := CompareVersions(versions[i], versions[j], format)
+		return c < 0
+	})
+
+	return nil
+}
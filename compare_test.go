@@ -0,0 +1,91 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		format   string
+		expected int
+	}{
+		{"1.2.3", "1.2.4", SourceSemVer, -1},
+		{"v1.2.3", "1.2.3+abcdef12", SourceSemVer, 0},
+		{"1.10.0", "1.9.0", "", 1},
+		{"1.3.0-alpha.1704164645", "1.3.0", SourceSemVer, -1},
+		{"v0.0.0-20240101120000-abcdef123456", "v0.1.0", SourceGo, -1},
+		{"1.2.3rc1", "1.2.3", SourcePython, -1},
+		{"1.2.3.post1", "1.2.3", SourcePython, 1},
+		{"1!1.0", "2.0", SourcePython, 1},
+		{"1.2.3~rc1", "1.2.3", SourceDebian, -1},
+		{"1:1.0", "2.0", SourceDebian, 1},
+		{"1.2.3^git1", "1.2.3", SourceRPM, 1},
+		{"1.2.3~rc1", "1.2.3~rc1", SourceRPM, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format+" "+test.a+" "+test.b, func(t *testing.T) {
+			c, err := CompareVersions(test.a, test.b, test.format)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, c)
+
+			c, err = CompareVersions(test.b, test.a, test.format)
+			require.NoError(t, err)
+			require.Equal(t, -test.expected, c)
+		})
+	}
+
+	t.Run("Invalid version", func(t *testing.T) {
+		_, err := CompareVersions("1.2.3", "1.2", SourceSemVer)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parsing version \"1.2\"")
+	})
+
+	t.Run("Invalid package versions", func(t *testing.T) {
+		_, err := CompareVersions("1.2.3", "latest", SourceDebian)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `invalid Debian version "latest"`)
+
+		_, err = CompareVersions("1.2.3 beta", "1.2.3", SourceRPM)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `invalid RPM version "1.2.3 beta"`)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, err := CompareVersions("1.2.3", "1.2.3", "maven")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown version format")
+	})
+}
+
+func TestSortVersions(t *testing.T) {
+	t.Run("Semantic versions", func(t *testing.T) {
+		versions := []string{"v1.10.0", "v1.2.0", "v1.2.0-rc.1", "v2.0.0", "v1.2.0-beta.2", "v1.2.0-beta.11"}
+		require.NoError(t, SortVersions(versions, SourceSemVer))
+		require.Equal(t, []string{"v1.2.0-beta.2", "v1.2.0-beta.11", "v1.2.0-rc.1", "v1.2.0", "v1.10.0", "v2.0.0"}, versions)
+	})
+
+	t.Run("PEP 440", func(t *testing.T) {
+		versions := []string{"1.0.post1", "1.0", "1.0rc1", "1.0.dev1", "1.0a1"}
+		require.NoError(t, SortVersions(versions, SourcePython))
+		require.Equal(t, []string{"1.0.dev1", "1.0a1", "1.0rc1", "1.0", "1.0.post1"}, versions)
+	})
+
+	t.Run("Equal versions keep their order", func(t *testing.T) {
+		versions := []string{"1.0.0+b", "0.9.0", "1.0.0+a"}
+		require.NoError(t, SortVersions(versions, SourceSemVer))
+		require.Equal(t, []string{"0.9.0", "1.0.0+b", "1.0.0+a"}, versions)
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		versions := []string{"1.2.0", "latest", "1.0.0"}
+		err := SortVersions(versions, SourceSemVer)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "latest")
+		require.Equal(t, []string{"1.2.0", "latest", "1.0.0"}, versions)
+	})
+}
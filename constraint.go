@@ -0,0 +1,101 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// parseConstraint parses a version constraint. Constraints are ranges as
+// accepted by blang/semver (">=1.0.0 <2.0.0", "1.x", "<1.0.0 || >=2.0.0")
+// plus the npm and Cargo shorthands "~1.4" (>=1.4.0 <1.5.0) and "^1.4"
+// (>=1.4.0 <2.0.0). As in npm, a pre-release is only within the range if
+// its release is, or the constraint names a pre-release of that release,
+// so 1.5.0-alpha.1 is not within ~1.4.
+func parseConstraint(constraint string) (semver.Range, error) {
+	var alternatives []string
+	preReleases := make(map[string]bool)
+	for _, alternative := range strings.Split(constraint, "||") {
+		var terms []string
+		for _, term := range strings.Fields(alternative) {
+			expanded, err := expandConstraintTerm(term)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+			}
+			terms = append(terms, expanded)
+
+			for _, comparison := range strings.Fields(expanded) {
+				version, err := semver.Parse(strings.TrimLeft(comparison, "<>=!v"))
+				if err == nil && len(version.Pre) > 0 {
+					preReleases[releaseOf(version).String()] = true
+				}
+			}
+		}
+		alternatives = append(alternatives, strings.Join(terms, " "))
+	}
+
+	inRange, err := semver.ParseRange(strings.Join(alternatives, " || "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	return func(version semver.Version) bool {
+		if !inRange(version) {
+			return false
+		}
+		if len(version.Pre) == 0 {
+			return true
+		}
+		release := releaseOf(version)
+		return inRange(release) || preReleases[release.String()]
+	}, nil
+}
+
+// releaseOf returns the release a version leads up to, without its
+// pre-release and build metadata
+func releaseOf(version semver.Version) semver.Version {
+	return semver.Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch}
+}
+
+// expandConstraintTerm rewrites a tilde or caret term as the equivalent
+// pair of comparisons, leaving other terms unchanged
+func expandConstraintTerm(term string) (string, error) {
+	operator := term[:1]
+	if operator != "~" && operator != "^" {
+		return term, nil
+	}
+
+	// Partial versions are padded with zeros: ~1.4 is >=1.4.0
+	version := strings.TrimPrefix(term[1:], "v")
+	suffix := ""
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version, suffix = version[:i], version[i:]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("%q has more than three version parts", term)
+	}
+
+	lower, err := semver.Parse(strings.Join(append(parts, "0", "0")[:3], ".") + suffix)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", term, err)
+	}
+	lower.Build = nil
+
+	upper := semver.Version{Major: lower.Major + 1}
+	switch {
+	case operator == "~" && len(parts) > 1:
+		// ~1.4 and ~1.4.2 allow patch releases
+		upper = semver.Version{Major: lower.Major, Minor: lower.Minor + 1}
+	case operator == "^" && lower.Major == 0 && (lower.Minor > 0 || len(parts) == 2):
+		// ^0.4 and ^0.4.2 allow patch releases, as does ^0.0
+		upper = semver.Version{Minor: lower.Minor + 1}
+	case operator == "^" && lower.Major == 0 && len(parts) == 3:
+		// ^0.0.3 allows only 0.0.3
+		upper = semver.Version{Patch: lower.Patch + 1}
+	}
+
+	return fmt.Sprintf(">=%s <%s", lower, upper), nil
+}
@@ -0,0 +1,163 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandConstraintTerm(t *testing.T) {
+	tests := []struct {
+		term     string
+		expected string
+	}{
+		{">=1.0.0", ">=1.0.0"},
+		{"~1", ">=1.0.0 <2.0.0"},
+		{"~1.4", ">=1.4.0 <1.5.0"},
+		{"~v1.4.2", ">=1.4.2 <1.5.0"},
+		{"~1.4.0-rc.1", ">=1.4.0-rc.1 <1.5.0"},
+		{"^1.4", ">=1.4.0 <2.0.0"},
+		{"^0.4.2", ">=0.4.2 <0.5.0"},
+		{"^0.0", ">=0.0.0 <0.1.0"},
+		{"^0.0.3", ">=0.0.3 <0.0.4"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.term, func(t *testing.T) {
+			expanded, err := expandConstraintTerm(test.term)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, expanded)
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		included   []string
+		excluded   []string
+	}{
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{"~1.4", []string{"1.4.0", "1.4.12"}, []string{"1.3.9", "1.5.0", "2.0.0"}},
+		{"^1.4", []string{"1.4.0", "1.10.0"}, []string{"1.3.0", "2.0.0"}},
+		{"~1.4 || ~2.1", []string{"1.4.3", "2.1.0"}, []string{"1.5.0", "2.0.0"}},
+		{"~1.4", []string{"1.4.3-rc.1"}, []string{"1.5.0-alpha.1", "1.4.0-rc.1"}},
+		{">=1.4.0-rc.1 <1.4.0", []string{"1.4.0-rc.1", "1.4.0-rc.2"}, []string{"1.4.0", "1.3.9"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			inRange, err := parseConstraint(test.constraint)
+			require.NoError(t, err)
+
+			for _, version := range test.included {
+				require.True(t, inRange(semver.MustParse(version)), version)
+			}
+			for _, version := range test.excluded {
+				require.False(t, inRange(semver.MustParse(version)), version)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, constraint := range []string{"~1.x", "^1.2.3.4", ">=one"} {
+			_, err := parseConstraint(constraint)
+			require.Error(t, err, constraint)
+			require.Contains(t, err.Error(), "invalid version constraint")
+		}
+	})
+}
+
+func TestCalculateWithConstraint(t *testing.T) {
+	repo := testRepoReleaseHistory(t, []string{"v1.4.2", "v2.0.0"}, nil)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "file.txt", "fix")
+	_, err = workTree.Commit("Backport fix", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	t.Run("Without constraint", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+		require.NoError(t, err)
+		require.Equal(t, "2.1.0-alpha", components.Semver.String())
+	})
+
+	t.Run("Patch bump within constraint", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Constraint: "~1.4",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "v1.4.2", components.BaseTag)
+		require.Equal(t, "1.4.3-alpha", components.Semver.String())
+		require.Equal(t, 2, components.Distance)
+		require.Contains(t, components.Overrides, "patch bump to stay within constraint ~1.4")
+	})
+
+	t.Run("Minor bump within constraint", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Constraint: ">=1.0.0 <2.0.0",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.5.0-alpha", components.Semver.String())
+		require.Empty(t, components.Overrides)
+	})
+
+	t.Run("Exact tag within constraint", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD~2"),
+			Constraint: "~1.4",
+		})
+		require.NoError(t, err)
+		require.True(t, components.IsExact)
+		require.Equal(t, "1.4.2", components.Semver.String())
+	})
+
+	t.Run("No bump within constraint", func(t *testing.T) {
+		_, _, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Constraint: ">=1.4.0 <=1.4.2",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no version bump from 1.4.2 stays within constraint >=1.4.0 <=1.4.2")
+	})
+
+	t.Run("Invalid constraint", func(t *testing.T) {
+		_, _, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Constraint: "~1.x",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid version constraint")
+	})
+}
+
+func TestCalculateWithConstraintPreReleaseTags(t *testing.T) {
+	repo := testRepoReleaseHistory(t, []string{"v1.4.2", "v1.5.0-alpha.1"}, nil)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "file.txt", "fix")
+	_, err = workTree.Commit("Backport fix", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	_, components, err := CalculateWithComponents(Options{
+		Repository: repo,
+		Commitish:  plumbing.Revision("HEAD"),
+		Constraint: "~1.4",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.2", components.BaseTag)
+	require.Equal(t, "1.4.3-alpha", components.Semver.String())
+	require.Equal(t, []string{"patch bump to stay within constraint ~1.4"}, components.Overrides)
+}
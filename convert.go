@@ -0,0 +1,324 @@
+package vers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// Source formats accepted by ConvertString
+const (
+	SourceAuto   = "auto"
+	SourceSemVer = "semver"
+	SourcePython = "python"
+	SourceDebian = "debian"
+	SourceRPM    = "rpm"
+	SourceGo     = "go"
+)
+
+var (
+	// goPseudoVersionRe is the pseudo-version pattern of golang.org/x/mod,
+	// vX.0.0-yyyymmddhhmmss-abcdefabcdef, vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef
+	// or vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef
+	goPseudoVersionRe = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+	// packageUpstreamRe matches the upstream versions vers produces for
+	// Debian and RPM: 1.2.3[~pre][^post][+build]
+	packageUpstreamRe = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:~([0-9A-Za-z.]+))?(?:\^([0-9A-Za-z.]+))?(?:\+([0-9A-Za-z.]+))?$`)
+
+	debianEpochRe = regexp.MustCompile(`^\d+:`)
+	commitHashRe  = regexp.MustCompile(`^g[0-9a-f]{7,40}$`)
+	alnumSplitRe  = regexp.MustCompile(`[0-9]+|[A-Za-z]+`)
+)
+
+// goPseudoLabel is the pre-release label given to pseudo-versions without
+// a pre-release, matching the label vers uses for builds past a tag
+const goPseudoLabel = "alpha"
+
+// Conversion is a version string converted from a source format into
+// every target format
+type Conversion struct {
+	// Source is the format the version was parsed as, detected when
+	// SourceAuto was requested
+	Source string
+
+	// Versions holds the target formats. The source format itself is
+	// re-emitted unchanged, or normalized for PEP 440.
+	Versions *LanguageVersions
+
+	// Lossy describes information in the source version that the other
+	// formats cannot carry, such as PEP 440 epochs or Debian revisions
+	Lossy []string
+}
+
+// ConvertString parses a version in the given source format, or detects
+// the format for SourceAuto, and converts it to every target format via
+// the semantic version model
+func ConvertString(version, source string) (*Conversion, error) {
+	if source == "" || source == SourceAuto {
+		source = detectSource(version)
+	}
+
+	conversion := &Conversion{Source: source}
+
+	var canonical string
+	var timestamp time.Time
+	var err error
+
+	switch source {
+	case SourceSemVer:
+		versions, err := fromSemVerString(version)
+		if err != nil {
+			return nil, err
+		}
+		conversion.Versions = versions
+		return conversion, nil
+	case SourcePython:
+		canonical, conversion.Lossy, err = semverFromPEP440(version)
+	case SourceDebian, SourceRPM:
+		canonical, conversion.Lossy, err = semverFromPackage(version, source)
+	case SourceGo:
+		canonical, timestamp, err = semverFromGoPseudo(version)
+	default:
+		return nil, fmt.Errorf("unknown source format %q (expected %s, %s, %s, %s, %s or %s)",
+			source, SourceAuto, SourceSemVer, SourcePython, SourceDebian, SourceRPM, SourceGo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := fromSemVerString(canonical)
+	if err != nil {
+		return nil, err
+	}
+	if versions.Version == nil {
+		return nil, fmt.Errorf("converting %s version %q: %q is not a valid semantic version", source, version, canonical)
+	}
+
+	switch source {
+	case SourcePython:
+		parsed, _ := ParsePEP440(version)
+		versions.Python = parsed.String()
+	case SourceDebian:
+		versions.Debian = version
+	case SourceRPM:
+		_, upstream, release := splitEVR(version)
+		versions.RPM = upstream
+		if release != "" {
+			versions.RPMRelease = release
+		}
+	case SourceGo:
+		versions.Go = version
+		versions.Version.Timestamp = timestamp
+	}
+
+	// Formats of the converted version include the re-emitted source
+	formats := *versions
+	formats.Version = nil
+	versions.Version.formats = &formats
+
+	conversion.Versions = versions
+	return conversion, nil
+}
+
+// detectSource guesses the format of a version string. Only versions with
+// markers unique to a format are detected as PEP 440, Debian, RPM or Go
+// pseudo-versions; anything else is treated as a semantic version.
+func detectSource(version string) string {
+	if goPseudoVersionRe.MatchString(version) {
+		return SourceGo
+	}
+
+	if _, err := semver.Parse(strings.TrimPrefix(version, "v")); err == nil {
+		return SourceSemVer
+	}
+
+	// Only RPM has "^" post-releases, and Debian takes "~" and epochs
+	if strings.Contains(version, "^") {
+		return SourceRPM
+	}
+	if strings.Contains(version, "~") || debianEpochRe.MatchString(version) {
+		return SourceDebian
+	}
+
+	// "-" is an alternative PEP 440 post-release spelling, but in practice
+	// marks a semantic version pre-release. Releases with other than three
+	// parts, such as 1.2a1 or 1.2.3.4rc1, need a pre-, post- or dev-release
+	// marker to tell them from x.y and x.y.z.w.
+	if parsed, err := ParsePEP440(version); err == nil && !strings.Contains(version, "-") &&
+		(len(parsed.Release) == 3 || len(parsed.Release) >= 2 && parsed.HasMarker()) {
+		return SourcePython
+	}
+
+	return SourceSemVer
+}
+
+// semverFromPEP440 maps a PEP 440 version onto a semantic version: a, b
+// and rc become alpha, beta and rc pre-releases, dev releases become dev
+// pre-release parts and local segments become build metadata
+func semverFromPEP440(version string) (string, []string, error) {
+	parsed, err := ParsePEP440(version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var lossy []string
+	if parsed.Epoch != 0 {
+		lossy = append(lossy, fmt.Sprintf("epoch %d dropped", parsed.Epoch))
+	}
+
+	release := make([]uint64, 3)
+	copy(release, parsed.Release)
+	if len(parsed.Release) > 3 {
+		lossy = append(lossy, fmt.Sprintf("release %s truncated to %d.%d.%d", joinUints(parsed.Release), release[0], release[1], release[2]))
+	}
+
+	var pre, build []string
+	if parsed.PreLabel != "" {
+		label := map[string]string{"a": "alpha", "b": "beta", "rc": "rc"}[parsed.PreLabel]
+		pre = append(pre, label, strconv.FormatUint(parsed.PreNumber, 10))
+	}
+	if parsed.HasDev {
+		if len(pre) > 0 {
+			lossy = append(lossy, "dev release of a pre-release sorts after the pre-release as a semantic version")
+		}
+		pre = append(pre, "dev", strconv.FormatUint(parsed.Dev, 10))
+	}
+	if parsed.HasPost {
+		lossy = append(lossy, fmt.Sprintf("post-release %d kept as build metadata, which does not affect ordering", parsed.Post))
+		build = append(build, "post", strconv.FormatUint(parsed.Post, 10))
+	}
+	build = append(build, buildIdentifiers(parsed.Local)...)
+
+	return formatSemVer(release[0], release[1], release[2], pre, build), lossy, nil
+}
+
+// semverFromPackage maps a Debian or RPM version onto a semantic version:
+// "~" introduces the pre-release and "+" the build metadata
+func semverFromPackage(version, source string) (string, []string, error) {
+	epoch, upstream, revision := splitEVR(version)
+
+	match := packageUpstreamRe.FindStringSubmatch(upstream)
+	if match == nil {
+		return "", nil, fmt.Errorf("unsupported %s version %q (expected [epoch:]major.minor.patch[~pre][+build][-revision])", source, version)
+	}
+
+	var lossy []string
+	if epoch != 0 {
+		lossy = append(lossy, fmt.Sprintf("epoch %d dropped", epoch))
+	}
+	if revision != "" && source == SourceDebian {
+		lossy = append(lossy, fmt.Sprintf("revision %s dropped", revision))
+	}
+
+	var pre, build []string
+	if match[4] != "" {
+		pre = splitAlnum(match[4])
+	}
+	if match[5] != "" {
+		lossy = append(lossy, fmt.Sprintf("post-release ^%s kept as build metadata, which does not affect ordering", match[5]))
+		build = append(build, splitAlnum(match[5])...)
+	}
+	if match[6] != "" {
+		build = append(build, buildIdentifiers(strings.Split(match[6], "."))...)
+	}
+
+	major, _ := strconv.ParseUint(match[1], 10, 64)
+	minor, _ := strconv.ParseUint(match[2], 10, 64)
+	patch, _ := strconv.ParseUint(match[3], 10, 64)
+
+	return formatSemVer(major, minor, patch, pre, build), lossy, nil
+}
+
+// semverFromGoPseudo maps a Go pseudo-version onto the version vers
+// calculates for an untagged commit: the base pre-release (alpha if none)
+// numbered by the commit's Unix timestamp, with the abbreviated hash as
+// build metadata
+func semverFromGoPseudo(version string) (string, time.Time, error) {
+	if !goPseudoVersionRe.MatchString(version) {
+		return "", time.Time{}, fmt.Errorf("invalid Go pseudo-version %q", version)
+	}
+
+	parsed, err := semver.Parse(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid Go pseudo-version %q: %w", version, err)
+	}
+
+	// The final identifier is yyyymmddhhmmss-hash, preceded by "0" unless
+	// the version has no base
+	last := parsed.Pre[len(parsed.Pre)-1].String()
+	stamp, hash, _ := strings.Cut(last, "-")
+
+	timestamp, err := time.Parse("20060102150405", stamp)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid Go pseudo-version %q: %w", version, err)
+	}
+
+	var pre []string
+	if len(parsed.Pre) > 2 {
+		for _, part := range parsed.Pre[:len(parsed.Pre)-2] {
+			pre = append(pre, part.String())
+		}
+	} else {
+		pre = []string{goPseudoLabel}
+	}
+	pre = append(pre, strconv.FormatInt(timestamp.Unix(), 10))
+
+	build := append([]string{hash}, parsed.Build...)
+
+	return formatSemVer(parsed.Major, parsed.Minor, parsed.Patch, pre, build), timestamp, nil
+}
+
+// buildIdentifiers converts local version segments into build metadata,
+// removing the "g" git adds to abbreviated hashes as vers does for Python,
+// Debian and RPM versions
+func buildIdentifiers(segments []string) []string {
+	identifiers := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		if i == 0 && commitHashRe.MatchString(segment) {
+			segment = segment[1:]
+		}
+		identifiers = append(identifiers, segment)
+	}
+	return identifiers
+}
+
+// splitAlnum splits package version parts into pre-release identifiers at
+// dots and letter/digit boundaries, so "rc1" becomes "rc", "1". Leading
+// zeros, which package managers ignore, are removed.
+func splitAlnum(s string) []string {
+	var identifiers []string
+	for _, part := range alnumSplitRe.FindAllString(s, -1) {
+		if isDigit(part[0]) {
+			n, err := strconv.ParseUint(part, 10, 64)
+			if err == nil {
+				part = strconv.FormatUint(n, 10)
+			}
+		}
+		identifiers = append(identifiers, part)
+	}
+	return identifiers
+}
+
+func formatSemVer(major, minor, patch uint64, pre, build []string) string {
+	version := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if len(pre) > 0 {
+		version += "-" + strings.Join(pre, ".")
+	}
+	if len(build) > 0 {
+		version += "+" + strings.Join(build, ".")
+	}
+	return version
+}
+
+func joinUints(values []uint64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatUint(v, 10)
+	}
+	return strings.Join(parts, ".")
+}
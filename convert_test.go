@@ -0,0 +1,241 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertString(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		source string
+		detect string
+		semver string
+		python string
+		debian string
+		goVer  string
+		lossy  []string
+	}{
+		{
+			name:   "Semantic version",
+			input:  "v1.2.3-rc.1",
+			detect: SourceSemVer,
+			semver: "1.2.3-rc.1",
+			python: "1.2.3rc1",
+			debian: "1.2.3~rc.1",
+			goVer:  "v1.2.3-rc.1",
+		},
+		{
+			name:   "PEP 440 pre-release",
+			input:  "1.2.3a1",
+			detect: SourcePython,
+			semver: "1.2.3-alpha.1",
+			python: "1.2.3a1",
+			debian: "1.2.3~alpha.1",
+			goVer:  "v1.2.3-alpha.1",
+		},
+		{
+			name:   "PEP 440 dev release with local version",
+			input:  "1.3.0.dev4+gabcdef12.dirty",
+			detect: SourcePython,
+			semver: "1.3.0-dev.4+abcdef12.dirty",
+			python: "1.3.0.dev4+gabcdef12.dirty",
+			debian: "1.3.0~dev.4+abcdef12.dirty",
+			goVer:  "v1.3.0-dev.4+abcdef12.dirty",
+		},
+		{
+			name:   "PEP 440 epoch and post-release",
+			input:  "1!1.2.3.post2",
+			source: SourcePython,
+			semver: "1.2.3+post.2",
+			python: "1!1.2.3.post2",
+			debian: "1.2.3+post.2",
+			goVer:  "v1.2.3+post.2",
+			lossy: []string{
+				"epoch 1 dropped",
+				"post-release 2 kept as build metadata, which does not affect ordering",
+			},
+		},
+		{
+			name:   "PEP 440 short release",
+			input:  "1.2rc1",
+			detect: SourcePython,
+			semver: "1.2.0-rc.1",
+			python: "1.2rc1",
+			debian: "1.2.0~rc.1",
+			goVer:  "v1.2.0-rc.1",
+		},
+		{
+			name:   "PEP 440 short alpha",
+			input:  "1.2a1",
+			detect: SourcePython,
+			semver: "1.2.0-alpha.1",
+			python: "1.2a1",
+			debian: "1.2.0~alpha.1",
+			goVer:  "v1.2.0-alpha.1",
+		},
+		{
+			name:   "PEP 440 short dev release",
+			input:  "2.0.dev3",
+			detect: SourcePython,
+			semver: "2.0.0-dev.3",
+			python: "2.0.dev3",
+			debian: "2.0.0~dev.3",
+			goVer:  "v2.0.0-dev.3",
+		},
+		{
+			name:   "PEP 440 short post-release",
+			input:  "1.2.post1",
+			detect: SourcePython,
+			semver: "1.2.0+post.1",
+			python: "1.2.post1",
+			debian: "1.2.0+post.1",
+			goVer:  "v1.2.0+post.1",
+			lossy:  []string{"post-release 1 kept as build metadata, which does not affect ordering"},
+		},
+		{
+			name:   "PEP 440 four-part release candidate",
+			input:  "1.2.3.4rc1",
+			detect: SourcePython,
+			semver: "1.2.3-rc.1",
+			python: "1.2.3.4rc1",
+			debian: "1.2.3~rc.1",
+			goVer:  "v1.2.3-rc.1",
+			lossy:  []string{"release 1.2.3.4 truncated to 1.2.3"},
+		},
+		{
+			name:   "Debian pre-release",
+			input:  "1.2.3~rc1",
+			detect: SourceDebian,
+			semver: "1.2.3-rc.1",
+			python: "1.2.3rc1",
+			debian: "1.2.3~rc1",
+			goVer:  "v1.2.3-rc.1",
+		},
+		{
+			name:   "Debian version from vers",
+			input:  "1:1.3.0~alpha.1704164645+gabcdef12-1",
+			detect: SourceDebian,
+			semver: "1.3.0-alpha.1704164645+abcdef12",
+			python: "1.3.0a1704164645+abcdef12",
+			debian: "1:1.3.0~alpha.1704164645+gabcdef12-1",
+			goVer:  "v1.3.0-alpha.1704164645+abcdef12",
+			lossy:  []string{"epoch 1 dropped", "revision 1 dropped"},
+		},
+		{
+			name:   "RPM snapshot",
+			input:  "1.2.3^20240101git1234567",
+			detect: SourceRPM,
+			semver: "1.2.3+20240101.git.1234567",
+			python: "1.2.3+20240101.git.1234567",
+			debian: "1.2.3+20240101.git.1234567",
+			goVer:  "v1.2.3+20240101.git.1234567",
+			lossy:  []string{"post-release ^20240101git1234567 kept as build metadata, which does not affect ordering"},
+		},
+		{
+			name:   "RPM post-release with epoch",
+			input:  "1:1.2.3^git1-2",
+			detect: SourceRPM,
+			semver: "1.2.3+git.1",
+			python: "1.2.3+git.1",
+			debian: "1.2.3+git.1",
+			goVer:  "v1.2.3+git.1",
+			lossy:  []string{"epoch 1 dropped", "post-release ^git1 kept as build metadata, which does not affect ordering"},
+		},
+		{
+			name:   "Go pseudo-version without a tag",
+			input:  "v0.0.0-20240101120000-abcdef123456",
+			detect: SourceGo,
+			semver: "0.0.0-alpha.1704110400+abcdef123456",
+			python: "0.0.0a1704110400+abcdef123456",
+			debian: "0.0.0~alpha.1704110400+abcdef123456",
+			goVer:  "v0.0.0-20240101120000-abcdef123456",
+		},
+		{
+			name:   "Go pseudo-version past a release",
+			input:  "v1.2.4-0.20240101120000-abcdef123456",
+			detect: SourceGo,
+			semver: "1.2.4-alpha.1704110400+abcdef123456",
+			python: "1.2.4a1704110400+abcdef123456",
+			debian: "1.2.4~alpha.1704110400+abcdef123456",
+			goVer:  "v1.2.4-0.20240101120000-abcdef123456",
+		},
+		{
+			name:   "Go pseudo-version past a pre-release",
+			input:  "v1.3.0-rc.1.0.20240101120000-abcdef123456+incompatible",
+			detect: SourceGo,
+			semver: "1.3.0-rc.1.1704110400+abcdef123456.incompatible",
+			python: "1.3.0.dev1+rc.1704110400.abcdef123456.incompatible",
+			debian: "1.3.0~rc.1.1704110400+abcdef123456.incompatible",
+			goVer:  "v1.3.0-rc.1.0.20240101120000-abcdef123456+incompatible",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := test.source
+			if source == "" {
+				source = SourceAuto
+			}
+
+			conversion, err := ConvertString(test.input, source)
+			require.NoError(t, err)
+
+			if test.detect != "" {
+				require.Equal(t, test.detect, conversion.Source)
+			}
+			require.Equal(t, test.semver, conversion.Versions.SemVer)
+			require.Equal(t, test.python, conversion.Versions.Python)
+			require.Equal(t, test.debian, conversion.Versions.Debian)
+			require.Equal(t, test.goVer, conversion.Versions.Go)
+			require.Equal(t, test.lossy, conversion.Lossy)
+
+			require.Regexp(t, semverGrammarRe, conversion.Versions.SemVer)
+			_, err = ParsePEP440(conversion.Versions.Python)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConvertStringVersion(t *testing.T) {
+	conversion, err := ConvertString("v1.2.4-0.20240101120000-abcdef123456", SourceAuto)
+	require.NoError(t, err)
+
+	version := conversion.Versions.Version
+	require.NotNil(t, version)
+	require.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), version.Timestamp)
+
+	// The re-emitted source is kept when formatting the converted version
+	goVersion, err := version.Format("go")
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.4-0.20240101120000-abcdef123456", goVersion)
+
+	release, err := Parse("1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, 1, version.Compare(release))
+}
+
+func TestConvertStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		source   string
+		expected string
+	}{
+		{"1.2", SourceAuto, "version must have exactly 3 parts"},
+		{"1.2.3", "cobol", "unknown source format"},
+		{"1.2~rc1", SourceDebian, "unsupported debian version"},
+		{"1.2.3", SourceGo, "invalid Go pseudo-version"},
+		{"not-a-version", SourcePython, "invalid PEP 440 version"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ConvertString(test.input, test.source)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package vers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// DockerLatestTag is the floating tag added to the tag set of releases
+const DockerLatestTag = "latest"
+
+// dockerTagMaxLength is the longest tag the OCI distribution spec allows
+const dockerTagMaxLength = 128
+
+var dockerTagInvalidRe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// DockerTag sanitizes a version or branch name to the OCI tag grammar,
+// [A-Za-z0-9_][A-Za-z0-9_.-]{0,127}. Invalid characters such as the "+"
+// of build metadata and the "/" of branch names become "-".
+func DockerTag(s string) string {
+	tag := dockerTagInvalidRe.ReplaceAllString(s, "-")
+	tag = strings.TrimLeft(tag, ".-")
+	if len(tag) > dockerTagMaxLength {
+		tag = tag[:dockerTagMaxLength]
+	}
+	return tag
+}
+
+// DockerTags expands a version into the image tags to publish. Clean
+// releases get their floating tags (1.2.3, 1.2, 1 and latest, omitting the
+// major tag for 0.x releases); anything else gets only its exact tag, plus
+// a tag for the branch being built when branchTag is set. Components may be
+// nil for converted versions, which are treated as exact.
+func DockerTags(versions *LanguageVersions, components *VersionComponents, branchTag bool) ([]string, error) {
+	var version semver.Version
+	var release bool
+	var branch string
+
+	if components == nil {
+		parsed, err := semver.Parse(versions.SemVer)
+		if err != nil {
+			return nil, fmt.Errorf("parsing version %q: %w", versions.SemVer, err)
+		}
+		version = parsed
+		release = len(parsed.Pre) == 0 && len(parsed.Build) == 0
+	} else {
+		version = components.Semver
+		release = components.verbatim() && !components.Dirty && len(components.Semver.Pre) == 0
+		branch = components.Branch
+	}
+
+	tags := []string{versions.Docker}
+
+	if release {
+		tags = append(tags, fmt.Sprintf("%d.%d", version.Major, version.Minor))
+		if version.Major > 0 {
+			tags = append(tags, fmt.Sprintf("%d", version.Major))
+		}
+		return append(tags, DockerLatestTag), nil
+	}
+
+	if branchTag && branch != "" {
+		if tag := DockerTag(branch); tag != "" && tag != versions.Docker {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
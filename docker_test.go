@@ -0,0 +1,107 @@
+package vers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"1.3.0-alpha.1704164645+abcdef12", "1.3.0-alpha.1704164645-abcdef12"},
+		{"1.3.0-alpha.1704164645+abcdef12.dirty", "1.3.0-alpha.1704164645-abcdef12.dirty"},
+		{"feature/login", "feature-login"},
+		{"-leading", "leading"},
+		{".hidden", "hidden"},
+		{"under_score", "under_score"},
+		{strings.Repeat("a", 200), strings.Repeat("a", 128)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			require.Equal(t, test.expected, DockerTag(test.input))
+		})
+	}
+}
+
+func TestDockerTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		docker     string
+		components *VersionComponents
+		branchTag  bool
+		expected   []string
+	}{
+		{
+			name:       "Release",
+			docker:     "1.2.3",
+			components: &VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, Branch: "main"},
+			branchTag:  true,
+			expected:   []string{"1.2.3", "1.2", "1", "latest"},
+		},
+		{
+			name:       "Zero major release",
+			docker:     "0.4.0",
+			components: &VersionComponents{Semver: semver.MustParse("0.4.0"), IsExact: true},
+			expected:   []string{"0.4.0", "0.4", "latest"},
+		},
+		{
+			name:       "Release candidate",
+			docker:     "1.3.0-rc.1",
+			components: &VersionComponents{Semver: semver.MustParse("1.3.0-rc.1"), IsExact: true, Branch: "main"},
+			expected:   []string{"1.3.0-rc.1"},
+		},
+		{
+			name:       "Untagged build with branch tag",
+			docker:     "1.3.0-alpha.1704164645-abcdef12",
+			components: &VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Branch: "feature/login"},
+			branchTag:  true,
+			expected:   []string{"1.3.0-alpha.1704164645-abcdef12", "feature-login"},
+		},
+		{
+			name:       "Untagged build without branch",
+			docker:     "1.3.0-alpha.1704164645-abcdef12",
+			components: &VersionComponents{Semver: semver.MustParse("1.3.0-alpha")},
+			branchTag:  true,
+			expected:   []string{"1.3.0-alpha.1704164645-abcdef12"},
+		},
+		{
+			name:       "Dirty release",
+			docker:     "1.2.3-dirty",
+			components: &VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, Dirty: true},
+			expected:   []string{"1.2.3-dirty"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags, err := DockerTags(&LanguageVersions{Docker: test.docker}, test.components, test.branchTag)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, tags)
+		})
+	}
+
+	t.Run("Converted version", func(t *testing.T) {
+		converted, err := CalculateFromString("v2.1.0")
+		require.NoError(t, err)
+
+		tags, err := DockerTags(converted, nil, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2.1.0", "2.1", "2", "latest"}, tags)
+	})
+
+	t.Run("Converted pre-release", func(t *testing.T) {
+		converted, err := CalculateFromString("2.1.0-beta.1+abcdef12")
+		require.NoError(t, err)
+
+		tags, err := DockerTags(converted, nil, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2.1.0-beta.1-abcdef12"}, tags)
+	})
+}
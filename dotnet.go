@@ -0,0 +1,153 @@
+package vers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Sources for the fourth part of the .NET FileVersion, see
+// Options.FileVersionRevision
+const (
+	FileVersionRevisionDistance    = "distance"
+	FileVersionRevisionBuildNumber = "build-number"
+	FileVersionRevisionZero        = "zero"
+)
+
+// dotnetMaxVersionPart is the largest value of each AssemblyVersion and
+// FileVersion part
+const dotnetMaxVersionPart = 65535
+
+// nugetV1NumberWidth zero-pads numbers in SemVer 1.0 pre-release labels,
+// which legacy NuGet clients compare as strings, so rc0002 sorts before rc0010
+const nugetV1NumberWidth = 4
+
+var nugetV1InvalidRe = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// dotnetVersions holds the .NET sub-formats of a version
+type dotnetVersions struct {
+	assembly      string
+	file          string
+	informational string
+	nugetV1       string
+
+	// warning explains why assembly or file is empty
+	warning string
+}
+
+func validateFileVersionRevision(source string) error {
+	switch source {
+	case "", FileVersionRevisionDistance, FileVersionRevisionBuildNumber, FileVersionRevisionZero:
+		return nil
+	default:
+		return fmt.Errorf("invalid file version revision source %q (expected %s, %s or %s)",
+			source, FileVersionRevisionDistance, FileVersionRevisionBuildNumber, FileVersionRevisionZero)
+	}
+}
+
+// buildDotNetVersions formats the AssemblyVersion (major.minor.patch.0),
+// FileVersion (major.minor.patch.revision), InformationalVersion (the
+// semantic version with the full commit hash) and NuGet SemVer 1.0 version.
+// The AssemblyVersion and FileVersion are left empty, with a warning, when
+// a part exceeds 65535, since they can't represent the version.
+func buildDotNetVersions(components *VersionComponents, opts Options) (dotnetVersions, error) {
+	version := components.Semver
+
+	var revision uint64
+	switch opts.FileVersionRevision {
+	case "", FileVersionRevisionDistance:
+		revision = uint64(components.Distance)
+	case FileVersionRevisionBuildNumber:
+		if components.BuildNumber != "" {
+			n, err := strconv.ParseUint(components.BuildNumber, 10, 64)
+			if err != nil {
+				return dotnetVersions{}, fmt.Errorf("build number %q is not numeric: %w", components.BuildNumber, err)
+			}
+			revision = n
+		}
+	}
+
+	parts := prereleaseParts(components)
+
+	informational := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if len(parts) > 0 {
+		informational += "-" + strings.Join(parts, ".")
+	}
+	if components.Hash != "" {
+		informational += "+" + components.Hash
+		if components.Dirty {
+			informational += ".dirty"
+		}
+	} else if components.Dirty {
+		informational += "+dirty"
+	}
+
+	versions := dotnetVersions{
+		assembly:      dotnetFourPartVersion(version.Major, version.Minor, version.Patch, 0),
+		file:          dotnetFourPartVersion(version.Major, version.Minor, version.Patch, revision),
+		informational: informational,
+		nugetV1:       nugetV1Version(version, parts),
+	}
+	if versions.file == "" {
+		versions.warning = fmt.Sprintf("no .NET FileVersion for %d.%d.%d revision %d: parts can't exceed %d",
+			version.Major, version.Minor, version.Patch, revision, dotnetMaxVersionPart)
+	}
+
+	return versions, nil
+}
+
+// dotnetFourPartVersion formats an AssemblyVersion or FileVersion, empty
+// if a part exceeds 65535
+func dotnetFourPartVersion(parts ...uint64) string {
+	formatted := make([]string, len(parts))
+	for i, part := range parts {
+		if part > dotnetMaxVersionPart {
+			return ""
+		}
+		formatted[i] = strconv.FormatUint(part, 10)
+	}
+	return strings.Join(formatted, ".")
+}
+
+// nugetV1Version formats a SemVer 1.0 version: a single alphanumeric
+// pre-release label with zero-padded numbers and no build metadata
+func nugetV1Version(version semver.Version, parts []string) string {
+	result := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if len(parts) == 0 {
+		return result
+	}
+
+	var label strings.Builder
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 64); err == nil && len(part) < nugetV1NumberWidth {
+			part = padLeft(part, nugetV1NumberWidth, "0")
+		}
+		label.WriteString(nugetV1InvalidRe.ReplaceAllString(part, ""))
+	}
+
+	return result + "-" + label.String()
+}
+
+// dotnetFromString converts an existing version string into the .NET
+// sub-formats, treating it as exact
+func dotnetFromString(version string) dotnetVersions {
+	parsed, err := semver.Parse(version)
+	if err != nil {
+		return dotnetVersions{informational: version, nugetV1: version}
+	}
+
+	var parts []string
+	for _, pre := range parsed.Pre {
+		parts = append(parts, pre.String())
+	}
+
+	return dotnetVersions{
+		assembly:      dotnetFourPartVersion(parsed.Major, parsed.Minor, parsed.Patch, 0),
+		file:          dotnetFourPartVersion(parsed.Major, parsed.Minor, parsed.Patch, 0),
+		informational: version,
+		nugetV1:       nugetV1Version(parsed, parts),
+	}
+}
@@ -0,0 +1,136 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDotNetVersions(t *testing.T) {
+	hash := "abcdef1234567890abcdef1234567890abcdef12"
+	timestamp := time.Unix(1704164645, 0)
+
+	t.Run("Release", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:  semver.MustParse("1.2.3"),
+			IsExact: true,
+			Hash:    hash,
+		}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, dotnetVersions{
+			assembly:      "1.2.3.0",
+			file:          "1.2.3.0",
+			informational: "1.2.3+" + hash,
+			nugetV1:       "1.2.3",
+		}, versions)
+	})
+
+	t.Run("Untagged build", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:    semver.MustParse("1.3.0-alpha"),
+			Hash:      hash,
+			Timestamp: timestamp,
+			Distance:  7,
+			Dirty:     true,
+		}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, dotnetVersions{
+			assembly:      "1.3.0.0",
+			file:          "1.3.0.7",
+			informational: "1.3.0-alpha.1704164645+" + hash + ".dirty",
+			nugetV1:       "1.3.0-alpha1704164645",
+		}, versions)
+	})
+
+	t.Run("Release candidate tag", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:  semver.MustParse("2.0.0-rc.2"),
+			IsExact: true,
+		}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0-rc0002", versions.nugetV1)
+		require.Equal(t, "2.0.0-rc.2", versions.informational)
+	})
+
+	t.Run("Build number revision", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:      semver.MustParse("1.3.0-alpha"),
+			Distance:    7,
+			BuildNumber: "1234",
+		}, Options{FileVersionRevision: FileVersionRevisionBuildNumber})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0.1234", versions.file)
+		require.Equal(t, "1.3.0-alpha1234", versions.nugetV1)
+	})
+
+	t.Run("Zero revision", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:   semver.MustParse("1.3.0-alpha"),
+			Distance: 7,
+		}, Options{FileVersionRevision: FileVersionRevisionZero})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0.0", versions.file)
+	})
+
+	t.Run("Distance out of range", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:   semver.MustParse("1.3.0-alpha"),
+			Distance: 70000,
+		}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0.0", versions.assembly)
+		require.Empty(t, versions.file)
+		require.Equal(t, "no .NET FileVersion for 1.3.0 revision 70000: parts can't exceed 65535", versions.warning)
+	})
+
+	t.Run("Version part out of range", func(t *testing.T) {
+		versions, err := buildDotNetVersions(&VersionComponents{
+			Semver:  semver.MustParse("2024.70000.1"),
+			IsExact: true,
+		}, Options{})
+		require.NoError(t, err)
+		require.Empty(t, versions.assembly)
+		require.Empty(t, versions.file)
+		require.NotEmpty(t, versions.warning)
+	})
+}
+
+func TestCalculateDotNetVersions(t *testing.T) {
+	t.Run("From archival", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3-4-g0123456", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0.0", version.DotNetAssembly)
+		require.Equal(t, "1.3.0.4", version.DotNetFile)
+		require.Equal(t, "1.3.0-alpha1704164645", version.NuGetV1)
+	})
+
+	t.Run("Build number out of range", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{
+			Archival:            testArchival("v1.2.3-4-g0123456", ""),
+			BuildNumber:         "70000",
+			FileVersionRevision: FileVersionRevisionBuildNumber,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0-alpha.70000+01234567", versions.SemVer)
+		require.Equal(t, "1.3.0.0", versions.DotNetAssembly)
+		require.Empty(t, versions.DotNetFile)
+		require.Equal(t, []string{"no .NET FileVersion for 1.3.0 revision 70000: parts can't exceed 65535"}, components.Warnings)
+	})
+
+	t.Run("Invalid revision source", func(t *testing.T) {
+		_, err := Calculate(Options{Archival: testArchival("v1.2.3", ""), FileVersionRevision: "timestamp"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file version revision source")
+	})
+
+	t.Run("From string", func(t *testing.T) {
+		version, err := CalculateFromString("v2.1.0-beta.3+abcdef12")
+		require.NoError(t, err)
+		require.Equal(t, "2.1.0.0", version.DotNetAssembly)
+		require.Equal(t, "2.1.0.0", version.DotNetFile)
+		require.Equal(t, "2.1.0-beta.3+abcdef12", version.DotNetInformational)
+		require.Equal(t, "2.1.0-beta0003", version.NuGetV1)
+	})
+}
@@ -0,0 +1,121 @@
+package vers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Environment variables read by ApplyEnvironment
+const (
+	// EnvOverride forces the reported version, e.g. "1.2.3" or "v2.0.0-rc.1"
+	EnvOverride = "VERS_OVERRIDE"
+
+	// EnvPrereleaseLabel replaces "alpha" for builds past a tag
+	EnvPrereleaseLabel = "VERS_PRERELEASE_LABEL"
+
+	// EnvBuildNumber replaces the commit timestamp in pre-release versions
+	EnvBuildNumber = "VERS_BUILD_NUMBER"
+
+	// EnvConstraint limits base versions to a range, e.g. "~1.4"
+	EnvConstraint = "VERS_CONSTRAINT"
+)
+
+// prereleaseLabels are the pre-release types every language format supports
+var prereleaseLabels = []string{"dev", "alpha", "beta", "rc"}
+
+// ApplyEnvironment copies the VERS_* override variables into opts, validating
+// each value. Variables that are unset or empty leave opts unchanged, and a
+// Constraint already set takes precedence over VERS_CONSTRAINT; getenv is
+// usually os.Getenv.
+func ApplyEnvironment(opts *Options, getenv func(string) string) error {
+	if value := getenv(EnvOverride); value != "" {
+		if _, err := parseOverride(value); err != nil {
+			return fmt.Errorf("%s: %w", EnvOverride, err)
+		}
+		opts.Override = value
+	}
+
+	if value := getenv(EnvPrereleaseLabel); value != "" {
+		if err := validatePrereleaseLabel(value); err != nil {
+			return fmt.Errorf("%s: %w", EnvPrereleaseLabel, err)
+		}
+		opts.PrereleaseLabel = value
+	}
+
+	if value := getenv(EnvBuildNumber); value != "" {
+		if err := validateBuildNumber(value); err != nil {
+			return fmt.Errorf("%s: %w", EnvBuildNumber, err)
+		}
+		opts.BuildNumber = value
+	}
+
+	if value := getenv(EnvConstraint); value != "" && opts.Constraint == "" {
+		if _, err := parseConstraint(value); err != nil {
+			return fmt.Errorf("%s: %w", EnvConstraint, err)
+		}
+		opts.Constraint = value
+	}
+
+	return nil
+}
+
+// validateOverrides checks the override options set directly or from the environment
+func validateOverrides(opts Options) error {
+	if opts.Override != "" {
+		if _, err := parseOverride(opts.Override); err != nil {
+			return err
+		}
+	}
+
+	if opts.PrereleaseLabel != "" {
+		if err := validatePrereleaseLabel(opts.PrereleaseLabel); err != nil {
+			return err
+		}
+	}
+
+	if opts.BuildNumber != "" {
+		if err := validateBuildNumber(opts.BuildNumber); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseOverride(value string) (semver.Version, error) {
+	version, err := semver.Parse(strings.TrimPrefix(value, "v"))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid override version %q: %w", value, err)
+	}
+
+	if len(version.Pre) > 0 {
+		if err := validatePrereleaseLabel(version.Pre[0].VersionStr); err != nil {
+			return semver.Version{}, fmt.Errorf("invalid override version %q: %w", value, err)
+		}
+	}
+
+	return version, nil
+}
+
+func validatePrereleaseLabel(label string) error {
+	for _, supported := range prereleaseLabels {
+		if label == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid prerelease label %q (must be one of %s)", label, strings.Join(prereleaseLabels, ", "))
+}
+
+func validateBuildNumber(value string) error {
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid build number %q: must be a non-negative integer", value)
+	}
+	// Semantic version pre-release numbers can't have leading zeros
+	if len(value) > 1 && value[0] == '0' {
+		return fmt.Errorf("invalid build number %q: must not have leading zeros", value)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func testGetenv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	t.Run("All variables set", func(t *testing.T) {
+		opts := Options{}
+		err := ApplyEnvironment(&opts, testGetenv(map[string]string{
+			EnvOverride:        "v1.2.3",
+			EnvPrereleaseLabel: "beta",
+			EnvBuildNumber:     "42",
+			EnvConstraint:      "~1.4",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "v1.2.3", opts.Override)
+		require.Equal(t, "beta", opts.PrereleaseLabel)
+		require.Equal(t, "42", opts.BuildNumber)
+		require.Equal(t, "~1.4", opts.Constraint)
+	})
+
+	t.Run("No variables set", func(t *testing.T) {
+		opts := Options{PrereleaseLabel: "rc"}
+		err := ApplyEnvironment(&opts, testGetenv(nil))
+		require.NoError(t, err)
+		require.Empty(t, opts.Override)
+		require.Equal(t, "rc", opts.PrereleaseLabel)
+	})
+
+	t.Run("Constraint already set", func(t *testing.T) {
+		opts := Options{Constraint: "^2"}
+		err := ApplyEnvironment(&opts, testGetenv(map[string]string{EnvConstraint: "~1.4"}))
+		require.NoError(t, err)
+		require.Equal(t, "^2", opts.Constraint)
+	})
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"Invalid override", map[string]string{EnvOverride: "1.2"}, "VERS_OVERRIDE: invalid override version"},
+		{"Unsupported override prerelease", map[string]string{EnvOverride: "1.2.3-preview.1"}, "invalid prerelease label"},
+		{"Invalid prerelease label", map[string]string{EnvPrereleaseLabel: "nightly"}, "VERS_PRERELEASE_LABEL: invalid prerelease label"},
+		{"Invalid build number", map[string]string{EnvBuildNumber: "-1"}, "VERS_BUILD_NUMBER: invalid build number"},
+		{"Build number with leading zeros", map[string]string{EnvBuildNumber: "007"}, `VERS_BUILD_NUMBER: invalid build number "007": must not have leading zeros`},
+		{"Invalid constraint", map[string]string{EnvConstraint: "~1.x"}, "VERS_CONSTRAINT: invalid version constraint"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ApplyEnvironment(&Options{}, testGetenv(test.env))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestCalculateWithOverrides(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoSingleCommitPastRelease(repo)
+	require.NoError(t, err)
+
+	t.Run("Override version", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Override:   "v3.1.4",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "3.1.4", versions.SemVer)
+		require.Equal(t, "v3.1.4", versions.Go)
+		require.True(t, components.Forced)
+		require.False(t, components.IsExact)
+		require.NotEmpty(t, components.BaseTag)
+		require.Equal(t, []string{"version forced to 3.1.4"}, components.Overrides)
+	})
+
+	t.Run("Override kept exactly as given", func(t *testing.T) {
+		versions, err := Calculate(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Override:   "3.1.4-rc+build.5",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "3.1.4-rc+build.5", versions.SemVer)
+		require.Equal(t, "3.1.4rc0+build.5", versions.Python)
+		require.Equal(t, "3.1.4~rc+build.5", versions.Debian)
+		require.Equal(t, "3.1.4-rc", versions.Maven)
+	})
+
+	t.Run("Override without repository", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{Override: "2.0.0"})
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0", versions.SemVer)
+		require.True(t, components.Forced)
+		require.Empty(t, components.BaseTag)
+		require.Equal(t, []string{"version forced to 2.0.0"}, components.Overrides)
+	})
+
+	t.Run("Override pre-release version", func(t *testing.T) {
+		versions, err := Calculate(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			Override:   "3.1.4-rc.2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "3.1.4-rc.2", versions.SemVer)
+		require.Equal(t, "3.1.4rc2", versions.Python)
+	})
+
+	t.Run("Prerelease label and build number", func(t *testing.T) {
+		versions, components, err := CalculateWithComponents(Options{
+			Repository:      repo,
+			Commitish:       plumbing.Revision("HEAD"),
+			OmitCommitHash:  true,
+			PrereleaseLabel: "beta",
+			BuildNumber:     "42",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.1.0-beta.42", versions.SemVer)
+		require.Equal(t, "1.1.0b42", versions.Python)
+		require.Equal(t, []string{"prerelease label set to beta", "build number set to 42"}, components.Overrides)
+	})
+
+	t.Run("No overrides", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+		})
+		require.NoError(t, err)
+		require.Empty(t, components.Overrides)
+		require.False(t, components.IsExact)
+		require.NotEmpty(t, components.BaseTag)
+	})
+
+	t.Run("Invalid build number", func(t *testing.T) {
+		_, err := Calculate(Options{
+			Repository:  repo,
+			Commitish:   plumbing.Revision("HEAD"),
+			BuildNumber: "abc",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid build number")
+	})
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/jaxxstorm/vers"
 )
@@ -25,9 +27,11 @@ func main() {
 	}
 
 	versions, err := vers.Calculate(opts)
-	if err != nil {
+	if errors.Is(err, vers.ErrNoCommits) {
 		// Handle repositories with no history
 		versions = vers.GenerateFallbackVersion()
+	} else if err != nil {
+		log.Fatal(err)
 	}
 
 	fmt.Printf("SemVer: %s\n", versions.SemVer)
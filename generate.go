@@ -0,0 +1,123 @@
+package vers
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Names of the identifiers written by GenerateGo and set by GoLDFlags
+const (
+	GoVersionName    = "Version"
+	GoCommitName     = "Commit"
+	GoCommitTimeName = "CommitTime"
+	GoDirtyName      = "Dirty"
+)
+
+var goSourceTemplate = template.Must(template.New("go").Parse(`// Code generated by vers; DO NOT EDIT.
+
+package {{.Package}}
+
+const (
+	// {{.VersionName}} is the semantic version of this build
+	{{.VersionName}} = {{printf "%q" .Version}}
+
+	// {{.CommitName}} is the full hash of the commit this build was made from
+	{{.CommitName}} = {{printf "%q" .Commit}}
+
+	// {{.CommitTimeName}} is the commit timestamp in RFC 3339 format
+	{{.CommitTimeName}} = {{printf "%q" .CommitTime}}
+
+	// {{.DirtyName}} reports whether the working tree had uncommitted changes
+	{{.DirtyName}} = {{.Dirty}}
+)
+`))
+
+// goBuildInfo holds the values shared by GenerateGo and GoLDFlags
+type goBuildInfo struct {
+	Version    string
+	Commit     string
+	CommitTime string
+	Dirty      bool
+}
+
+func newGoBuildInfo(versions *LanguageVersions, components *VersionComponents) goBuildInfo {
+	info := goBuildInfo{Version: versions.SemVer}
+	if components != nil {
+		info.Commit = components.Hash
+		info.Dirty = components.Dirty
+		if !components.Timestamp.IsZero() {
+			info.CommitTime = components.Timestamp.UTC().Format(time.RFC3339)
+		}
+	}
+	return info
+}
+
+// GenerateGo returns gofmt'd Go source declaring Version, Commit,
+// CommitTime and Dirty constants in the given package
+func GenerateGo(pkg string, versions *LanguageVersions, components *VersionComponents) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	data := struct {
+		goBuildInfo
+		Package        string
+		VersionName    string
+		CommitName     string
+		CommitTimeName string
+		DirtyName      string
+	}{
+		goBuildInfo:    newGoBuildInfo(versions, components),
+		Package:        pkg,
+		VersionName:    GoVersionName,
+		CommitName:     GoCommitName,
+		CommitTimeName: GoCommitTimeName,
+		DirtyName:      GoDirtyName,
+	}
+
+	var buf bytes.Buffer
+	if err := goSourceTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("generating Go source: %w", err)
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// GoLDFlags returns -X linker flags setting the Version, Commit, CommitTime
+// and Dirty string variables of the package at importPath, e.g. for
+// go build -ldflags "$(vers generate go --ldflags example.com/app/version)"
+func GoLDFlags(importPath string, versions *LanguageVersions, components *VersionComponents) string {
+	info := newGoBuildInfo(versions, components)
+
+	values := []struct{ name, value string }{
+		{GoVersionName, info.Version},
+		{GoCommitName, info.Commit},
+		{GoCommitTimeName, info.CommitTime},
+		{GoDirtyName, strconv.FormatBool(info.Dirty)},
+	}
+
+	flags := make([]string, 0, len(values))
+	for _, v := range values {
+		flags = append(flags, "-X "+ldflagQuote(importPath+"."+v.name+"="+v.value))
+	}
+
+	return strings.Join(flags, " ")
+}
+
+// ldflagQuote quotes a -X argument containing whitespace so the go tool
+// splits -ldflags correctly; it accepts single or double quotes but no escapes
+func ldflagQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\n") {
+		return s
+	}
+	if strings.Contains(s, "'") {
+		return `"` + s + `"`
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,68 @@
+package vers
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGo(t *testing.T) {
+	versions := &LanguageVersions{SemVer: "1.3.0-alpha.1704164645+abcdef12"}
+	components := &VersionComponents{
+		Semver:    semver.MustParse("1.3.0-alpha"),
+		Hash:      "abcdef1234567890abcdef1234567890abcdef12",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+		Dirty:     true,
+	}
+
+	t.Run("Valid source", func(t *testing.T) {
+		source, err := GenerateGo("buildinfo", versions, components)
+		require.NoError(t, err)
+
+		file, err := parser.ParseFile(token.NewFileSet(), "version_gen.go", source, parser.ParseComments)
+		require.NoError(t, err)
+		require.Equal(t, "buildinfo", file.Name.Name)
+
+		content := string(source)
+		require.Contains(t, content, "// Code generated by vers; DO NOT EDIT.")
+		require.Contains(t, content, `Version = "1.3.0-alpha.1704164645+abcdef12"`)
+		require.Contains(t, content, `Commit = "abcdef1234567890abcdef1234567890abcdef12"`)
+		require.Contains(t, content, `CommitTime = "2024-01-02T02:04:05Z"`)
+		require.Contains(t, content, "Dirty = true")
+	})
+
+	t.Run("Without components", func(t *testing.T) {
+		source, err := GenerateGo("version", GenerateFallbackVersion(), nil)
+		require.NoError(t, err)
+		require.Contains(t, string(source), `Commit = ""`)
+		require.Contains(t, string(source), "Dirty = false")
+	})
+
+	t.Run("Invalid package name", func(t *testing.T) {
+		_, err := GenerateGo("my-version", versions, components)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid package name")
+	})
+}
+
+func TestGoLDFlags(t *testing.T) {
+	versions := &LanguageVersions{SemVer: "1.2.3"}
+	components := &VersionComponents{
+		Hash:      "abcdef1234567890abcdef1234567890abcdef12",
+		Timestamp: time.Unix(1704164645, 0),
+	}
+
+	require.Equal(t,
+		"-X example.com/app/version.Version=1.2.3"+
+			" -X example.com/app/version.Commit=abcdef1234567890abcdef1234567890abcdef12"+
+			" -X example.com/app/version.CommitTime=2024-01-02T03:04:05Z"+
+			" -X example.com/app/version.Dirty=false",
+		GoLDFlags("example.com/app/version", versions, components))
+
+	require.Equal(t, "'a.B=x y'", ldflagQuote("a.B=x y"))
+	require.Equal(t, `"a.B=it's x"`, ldflagQuote("a.B=it's x"))
+}
@@ -5,6 +5,8 @@
 package vers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path"
@@ -18,6 +20,10 @@ import (
 	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
+// ErrNoCommits is returned when the repository has no commits to version,
+// e.g. right after git init
+var ErrNoCommits = errors.New("repository has no commits")
+
 // OpenRepository opens a Git repository at the specified path
 func OpenRepository(path string) (*git.Repository, error) {
 	return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{
@@ -26,9 +32,22 @@ func OpenRepository(path string) (*git.Repository, error) {
 	})
 }
 
-func getVersionComponents(opts Options) (*VersionComponents, error) {
+func getVersionComponents(ctx context.Context, opts Options) (*VersionComponents, error) {
+	if opts.Repository == nil && opts.Archival == nil {
+		return overrideComponents(opts)
+	}
+	if opts.Repository == nil {
+		return getArchivalComponents(opts)
+	}
+
 	revision, err := opts.Repository.ResolveRevision(opts.Commitish)
 	if err != nil {
+		if _, headErr := opts.Repository.Head(); errors.Is(headErr, plumbing.ErrReferenceNotFound) {
+			if opts.Override != "" {
+				return overrideComponents(opts)
+			}
+			return nil, ErrNoCommits
+		}
 		return nil, fmt.Errorf("resolving commitish: %w", err)
 	}
 
@@ -37,80 +56,286 @@ func getVersionComponents(opts Options) (*VersionComponents, error) {
 		return nil, fmt.Errorf("getting commit object: %w", err)
 	}
 
-	baseVersion, isExact, err := determineBaseVersion(
+	baseTag, baseCommit, isExact, err := determineBaseVersion(ctx,
 		opts.Repository, revision, opts.IsPreRelease, opts.TagFilter)
 	if err != nil {
 		return nil, fmt.Errorf("determining base version: %w", err)
 	}
 
+	distance := 0
+	if !isExact {
+		if tag, ok := ciExactTag(opts, revision.String()); ok {
+			baseTag, isExact = tag, true
+		} else {
+			distance, err = commitDistance(ctx, opts.Repository, commit, baseCommit)
+			if err != nil {
+				return nil, fmt.Errorf("counting commits since %s: %w", baseTag, err)
+			}
+		}
+	}
+
+	var apiReport *APIReport
+	if opts.APIBump && !isExact && baseTag != "" && opts.Override == "" {
+		dir := opts.ModuleDir
+		if dir == "" {
+			dir = apiDir(baseTag)
+		}
+		apiReport, err = diffAPI(ctx, opts.Repository, baseCommit, *revision, dir)
+		if err != nil {
+			return nil, fmt.Errorf("comparing API with %s: %w", baseTag, err)
+		}
+	}
+
+	version, overrides, err := resolveVersion(baseTag, isExact, apiReport, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	modulePath, err := GoModulePath(opts.Repository, *revision, opts.ModuleDir)
+	switch {
+	case err != nil:
+		warnings = append(warnings, err.Error())
+	case modulePath != "":
+		if err := CheckGoModulePath(modulePath, version); err != nil {
+			if opts.StrictGoModule {
+				return nil, err
+			}
+			warnings = append(warnings, err.Error())
+		}
+	}
+
+	isDirty, err := workTreeIsDirty(ctx, opts.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("checking if worktree is dirty: %w", err)
+	}
+
+	components := &VersionComponents{
+		Semver:      version,
+		Dirty:       isDirty,
+		Hash:        revision.String(),
+		ShortHash:   revision.String()[:8],
+		Timestamp:   commit.Committer.When,
+		IsExact:     isExact,
+		BaseTag:     baseTag,
+		Distance:    distance,
+		BuildNumber: opts.BuildNumber,
+		Overrides:   overrides,
+		Forced:      opts.Override != "",
+		APIReport:   apiReport,
+		Warnings:    warnings,
+	}
+	components.Branch, components.PullRequest = branchContext(opts)
+
+	return components, nil
+}
+
+// overrideComponents builds the components of a forced version outside a
+// repository or archive, where there is no commit to describe
+func overrideComponents(opts Options) (*VersionComponents, error) {
+	version, overrides, err := resolveVersion("", false, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	components := &VersionComponents{
+		Semver:      version,
+		BuildNumber: opts.BuildNumber,
+		Overrides:   overrides,
+		Forced:      true,
+	}
+	components.Branch, components.PullRequest = branchContext(opts)
+
+	return components, nil
+}
+
+// branchContext determines the branch being built, preferring the CI context
+// since CI checkouts are usually a detached HEAD
+func branchContext(opts Options) (string, string) {
+	if opts.CI != nil && (opts.CI.Branch != "" || opts.CI.PullRequest != "") {
+		return opts.CI.Branch, opts.CI.PullRequest
+	}
+
+	if opts.Repository == nil || opts.Commitish != "HEAD" {
+		return "", ""
+	}
+
+	head, err := opts.Repository.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return "", ""
+	}
+
+	return head.Name().Short(), ""
+}
+
+// resolveVersion parses the version from the base tag and applies the
+// standard increment for builds past a tag, or the one the API report calls
+// for, followed by any overrides. It returns a description of each override
+// applied.
+func resolveVersion(baseTag string, isExact bool, apiReport *APIReport, opts Options) (semver.Version, []string, error) {
+	var overrides []string
+
+	if opts.Override != "" {
+		version, err := parseOverride(opts.Override)
+		if err != nil {
+			return semver.Version{}, nil, err
+		}
+		overrides = append(overrides, fmt.Sprintf("version forced to %s", version))
+		return version, overrides, nil
+	}
+
+	baseVersion := "0.0.0"
+	if baseTag != "" {
+		baseVersion = stripModuleTagPrefixes(baseTag)
+	}
+
 	version, err := semver.Parse(baseVersion)
 	if err != nil {
-		return nil, fmt.Errorf("parsing base version %q: %w", baseVersion, err)
+		return semver.Version{}, nil, fmt.Errorf("parsing base version %q: %w", baseVersion, err)
 	}
 
 	// Increment version for non-exact matches
 	if !isExact {
-		if version.Major == 0 {
-			version.Patch++
-		} else {
-			version.Minor++
-			version.Patch = 0
+		base := version
+
+		level := BumpLevelMinor
+		if apiReport != nil {
+			level = apiReport.Bump
+			overrides = append(overrides, fmt.Sprintf("%s bump for %s", level, describeAPIReport(apiReport)))
 		}
-		version.Pre = []semver.PRVersion{{VersionStr: "alpha"}}
+		version = bumpLevel(base, level)
+
+		// Maintenance branches get smaller bumps within their constraint
+		if opts.Constraint != "" {
+			inRange, err := parseConstraint(opts.Constraint)
+			if err != nil {
+				return semver.Version{}, nil, err
+			}
+
+			wanted := level
+			for !inRange(releaseOf(version)) && level != BumpLevelPatch {
+				if level == BumpLevelMajor {
+					level = BumpLevelMinor
+				} else {
+					level = BumpLevelPatch
+				}
+				version = bumpLevel(base, level)
+			}
+			if !inRange(releaseOf(version)) {
+				return semver.Version{}, nil, fmt.Errorf("no version bump from %s stays within constraint %s", base, opts.Constraint)
+			}
+			if level != wanted {
+				overrides = append(overrides, fmt.Sprintf("%s bump to stay within constraint %s", level, opts.Constraint))
+			}
+		}
+
+		label := "alpha"
+		switch {
+		case opts.PrereleaseLabel != "":
+			label = opts.PrereleaseLabel
+			overrides = append(overrides, fmt.Sprintf("prerelease label set to %s", label))
+		case opts.CI != nil && opts.CI.PullRequest != "":
+			label = "dev"
+			overrides = append(overrides, fmt.Sprintf("prerelease label set to dev for pull request %s", opts.CI.PullRequest))
+		}
+		version.Pre = []semver.PRVersion{{VersionStr: label}}
 	}
 
 	// Apply release prefix override
 	if opts.ReleasePrefix != "" {
 		newVersion, err := semver.Parse(opts.ReleasePrefix)
 		if err != nil {
-			return nil, fmt.Errorf("parsing release prefix %q: %w", opts.ReleasePrefix, err)
+			return semver.Version{}, nil, fmt.Errorf("parsing release prefix %q: %w", opts.ReleasePrefix, err)
 		}
 		version.Major = newVersion.Major
 		version.Minor = newVersion.Minor
 		version.Patch = newVersion.Patch
+		overrides = append(overrides, fmt.Sprintf("release prefix %s applied", opts.ReleasePrefix))
 	}
 
-	isDirty, err := workTreeIsDirty(opts.Repository)
-	if err != nil {
-		return nil, fmt.Errorf("checking if worktree is dirty: %w", err)
+	if opts.BuildNumber != "" && len(version.Pre) > 0 {
+		overrides = append(overrides, fmt.Sprintf("build number set to %s", opts.BuildNumber))
 	}
 
-	return &VersionComponents{
-		Semver:    version,
-		Dirty:     isDirty,
-		ShortHash: revision.String()[:8],
-		Timestamp: commit.Committer.When,
-		IsExact:   isExact,
-	}, nil
+	return version, overrides, nil
 }
 
-func determineBaseVersion(repo *git.Repository, revision *plumbing.Hash,
-	isPrerelease bool, tagFilter func(string) bool) (string, bool, error) {
+// determineBaseVersion finds the tag the version is derived from, returning
+// the short tag name (empty if none), the tagged commit and whether it is the
+// commit being analyzed
+func determineBaseVersion(ctx context.Context, repo *git.Repository, revision *plumbing.Hash,
+	isPrerelease bool, tagFilter func(string) bool) (string, plumbing.Hash, bool, error) {
 
 	commit, err := repo.CommitObject(*revision)
 	if err != nil {
-		return "", false, fmt.Errorf("getting commit object: %w", err)
+		return "", plumbing.ZeroHash, false, fmt.Errorf("getting commit object: %w", err)
 	}
 
 	// Check for exact tag match
 	isExact, exactMatch, err := isExactTag(repo, commit.Hash, isPrerelease, tagFilter)
 	if err != nil {
-		return "", false, fmt.Errorf("checking exact tag: %w", err)
+		return "", plumbing.ZeroHash, false, fmt.Errorf("checking exact tag: %w", err)
 	}
 	if isExact {
-		return stripModuleTagPrefixes(exactMatch.Name().Short()), true, nil
+		return exactMatch.Name().Short(), commit.Hash, true, nil
 	}
 
 	// Find most recent tag
-	hasRecent, recentMatch, err := mostRecentTag(repo, commit.Hash, isPrerelease, tagFilter)
+	hasRecent, recentMatch, err := mostRecentTag(ctx, repo, commit.Hash, isPrerelease, tagFilter)
 	if err != nil {
-		return "", false, fmt.Errorf("finding recent tag: %w", err)
+		return "", plumbing.ZeroHash, false, fmt.Errorf("finding recent tag: %w", err)
 	}
 	if hasRecent {
-		return stripModuleTagPrefixes(recentMatch.Name().Short()), false, nil
+		tagCommit, err := tagCommitHash(repo, recentMatch)
+		if err != nil {
+			return "", plumbing.ZeroHash, false, err
+		}
+		return recentMatch.Name().Short(), tagCommit, false, nil
+	}
+
+	return "", plumbing.ZeroHash, false, nil
+}
+
+// tagCommitHash returns the commit a lightweight or annotated tag points at
+func tagCommitHash(repo *git.Repository, ref *plumbing.Reference) (plumbing.Hash, error) {
+	obj, err := repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		return obj.Target, nil
+	case plumbing.ErrObjectNotFound:
+		return ref.Hash(), nil
+	default:
+		return plumbing.ZeroHash, fmt.Errorf("resolving tag %s: %w", ref.Name().Short(), err)
+	}
+}
+
+// commitDistance counts the commits reachable from head but not from base,
+// as git describe does. With a zero base every reachable commit is counted.
+func commitDistance(ctx context.Context, repo *git.Repository, head *object.Commit, base plumbing.Hash) (int, error) {
+	seen := make(map[plumbing.Hash]bool)
+
+	if !base.IsZero() {
+		baseCommit, err := repo.CommitObject(base)
+		if err != nil {
+			return 0, fmt.Errorf("getting commit object: %w", err)
+		}
+
+		err = object.NewCommitPreorderIter(baseCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return ctx.Err()
+		})
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	return "0.0.0", false, nil
+	distance := 0
+	err := object.NewCommitPreorderIter(head, seen, nil).ForEach(func(c *object.Commit) error {
+		distance++
+		return ctx.Err()
+	})
+
+	return distance, err
 }
 
 func stripModuleTagPrefixes(tag string) string {
@@ -118,6 +343,11 @@ func stripModuleTagPrefixes(tag string) string {
 	return strings.TrimPrefix(versionComponent, "v")
 }
 
+// parseTagVersion parses the semantic version encoded in a tag name
+func parseTagVersion(tag string) (semver.Version, error) {
+	return semver.Parse(stripModuleTagPrefixes(tag))
+}
+
 func isExactTag(repo *git.Repository, hash plumbing.Hash,
 	isPrerelease bool, tagFilter func(string) bool) (bool, *plumbing.Reference, error) {
 
@@ -132,15 +362,7 @@ func isExactTag(repo *git.Repository, hash plumbing.Hash,
 			return nil
 		}
 
-		refName := ref.Name().String()
-
-		// Skip beta/rc tags if not prerelease
-		if !isPrerelease && (strings.Contains(refName, "beta") || strings.Contains(refName, "rc")) {
-			return nil
-		}
-
-		// Apply tag filter
-		if tagFilter != nil && !tagFilter(strings.TrimPrefix(refName, "refs/tags/")) {
+		if !tagAllowed(ref.Name().String(), isPrerelease, tagFilter) {
 			return nil
 		}
 
@@ -168,7 +390,22 @@ func isExactTag(repo *git.Repository, hash plumbing.Hash,
 	return exactTag != nil, exactTag, err
 }
 
-func mostRecentTag(repo *git.Repository, ref plumbing.Hash,
+// tagAllowed reports whether a tag may be used as a base version
+func tagAllowed(refName string, isPrerelease bool, tagFilter func(string) bool) bool {
+	// Skip beta/rc tags if not prerelease
+	if !isPrerelease && (strings.Contains(refName, "beta") || strings.Contains(refName, "rc")) {
+		return false
+	}
+
+	// Apply tag filter
+	if tagFilter != nil && !tagFilter(strings.TrimPrefix(refName, "refs/tags/")) {
+		return false
+	}
+
+	return true
+}
+
+func mostRecentTag(ctx context.Context, repo *git.Repository, ref plumbing.Hash,
 	isPrerelease bool, tagFilter func(string) bool) (bool, *plumbing.Reference, error) {
 
 	commit, err := repo.CommitObject(ref)
@@ -180,6 +417,10 @@ func mostRecentTag(repo *git.Repository, ref plumbing.Hash,
 	walker := object.NewCommitPreorderIter(commit, nil, nil)
 
 	err = walker.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		isExact, exact, err := isExactTag(repo, commit.Hash, isPrerelease, tagFilter)
 		if err != nil {
 			return err
@@ -196,7 +437,7 @@ func mostRecentTag(repo *git.Repository, ref plumbing.Hash,
 	return mostRecentTag != nil, mostRecentTag, err
 }
 
-func workTreeIsDirty(repo *git.Repository) (bool, error) {
+func workTreeIsDirty(ctx context.Context, repo *git.Repository) (bool, error) {
 	workTree, err := repo.Worktree()
 	if err != nil {
 		return false, fmt.Errorf("getting worktree: %w", err)
@@ -204,7 +445,7 @@ func workTreeIsDirty(repo *git.Repository) (bool, error) {
 
 	// Fast path for filesystem storage
 	if _, ok := repo.Storer.(*filesystem.Storage); ok {
-		return checkDirtyWithGitCommand(workTree.Filesystem.Root())
+		return checkDirtyWithGitCommand(ctx, workTree.Filesystem.Root())
 	}
 
 	// Fallback to go-git status check
@@ -216,20 +457,27 @@ func workTreeIsDirty(repo *git.Repository) (bool, error) {
 	return !status.IsClean(), nil
 }
 
-func checkDirtyWithGitCommand(repoPath string) (bool, error) {
+func checkDirtyWithGitCommand(ctx context.Context, repoPath string) (bool, error) {
 	// Refresh index first
-	cmd := exec.Command("git", "update-index", "-q", "--refresh")
+	cmd := exec.CommandContext(ctx, "git", "update-index", "-q", "--refresh")
 	cmd.Dir = repoPath
 	if err := cmd.Run(); err != nil {
+		// A killed command says nothing about the worktree
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
 		// If update-index fails, assume dirty
 		return true, nil
 	}
 
 	// Check for changes
-	cmd = exec.Command("git", "diff-files", "--name-status", "--ignore-space-at-eol")
+	cmd = exec.CommandContext(ctx, "git", "diff-files", "--name-status", "--ignore-space-at-eol")
 	cmd.Dir = repoPath
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
 		if _, ok := err.(*exec.ExitError); ok {
 			return true, nil
 		}
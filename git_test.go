@@ -1,12 +1,14 @@
 package vers
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,7 +30,7 @@ func TestMostRecentTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, headRef)
 
-		hasMostRecent, mostRecent, err := mostRecentTag(repo, headRef.Hash(), false, nil)
+		hasMostRecent, mostRecent, err := mostRecentTag(context.Background(), repo, headRef.Hash(), false, nil)
 		require.NoError(t, err)
 		require.True(t, hasMostRecent)
 		require.NotNil(t, mostRecent)
@@ -48,7 +50,7 @@ func TestMostRecentTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, head)
 
-		hasMostRecent, mostRecent, err := mostRecentTag(repo, head, false, nil)
+		hasMostRecent, mostRecent, err := mostRecentTag(context.Background(), repo, head, false, nil)
 		require.NoError(t, err)
 		require.False(t, hasMostRecent)
 		require.Nil(t, mostRecent)
@@ -80,7 +82,7 @@ func TestMostRecentTag(t *testing.T) {
 			return !strings.Contains(tag, "/")
 		}
 
-		hasMostRecent, mostRecent, err := mostRecentTag(repo, commit, false, noSlashFilter)
+		hasMostRecent, mostRecent, err := mostRecentTag(context.Background(), repo, commit, false, noSlashFilter)
 		require.NoError(t, err)
 		require.True(t, hasMostRecent)
 		require.Equal(t, "refs/tags/v1.0.0", mostRecent.Name().String())
@@ -167,7 +169,7 @@ func TestWorkTreeIsDirty(t *testing.T) {
 	require.NotEmpty(t, head)
 
 	t.Run("Working tree is clean", func(t *testing.T) {
-		clean, err := workTreeIsDirty(repo)
+		clean, err := workTreeIsDirty(context.Background(), repo)
 		require.NoError(t, err)
 		require.False(t, clean)
 	})
@@ -187,10 +189,18 @@ func TestWorkTreeIsDirty(t *testing.T) {
 
 	t.Run("Working tree is dirty", func(t *testing.T) {
 		t.Skip("Skipping filesystem-dependent dirty check test")
-		dirty, err := workTreeIsDirty(repo)
+		dirty, err := workTreeIsDirty(context.Background(), repo)
 		require.NoError(t, err)
 		require.True(t, dirty)
 	})
+
+	t.Run("Cancelled git command", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := workTreeIsDirty(ctx, repo)
+		require.ErrorIs(t, err, context.Canceled)
+	})
 }
 
 func TestOpenRepository(t *testing.T) {
@@ -222,3 +232,38 @@ func TestOpenRepository(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestCommitDistance(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoSingleCommitPastRelease(repo)
+	require.NoError(t, err)
+
+	t.Run("Commits past tag", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{Repository: repo})
+		require.NoError(t, err)
+		require.Equal(t, 1, components.Distance)
+		require.Len(t, components.Hash, 40)
+	})
+
+	t.Run("Exact tag", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{Repository: repo, Commitish: "v1.0.0"})
+		require.NoError(t, err)
+		require.Equal(t, 0, components.Distance)
+		require.True(t, components.IsExact)
+	})
+
+	t.Run("No tags", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		head, err := testRepoSingleCommit(repo)
+		require.NoError(t, err)
+
+		commit, err := repo.CommitObject(head)
+		require.NoError(t, err)
+
+		distance, err := commitDistance(context.Background(), repo, commit, plumbing.ZeroHash)
+		require.NoError(t, err)
+		require.Equal(t, 1, distance)
+	})
+}
@@ -0,0 +1,124 @@
+package vers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var (
+	// goModuleRe matches the module directive of a go.mod file
+	goModuleRe = regexp.MustCompile(`(?m)^\s*module\s+("[^"]+"|\S+)`)
+
+	// moduleMajorRe matches the major version suffix of a module path,
+	// /vN for most paths and .vN for gopkg.in
+	moduleMajorRe = regexp.MustCompile(`(?:/|^gopkg\.in/.*\.)v(0|[1-9]\d*)$`)
+
+	// majorDirRe matches a major version subdirectory such as v2
+	majorDirRe = regexp.MustCompile(`^v([2-9]|[1-9]\d+)$`)
+)
+
+// GoModulePath returns the module path declared by the go.mod file in dir
+// (the repository root if empty) at a commit. It returns an empty path if
+// there is no go.mod file.
+func GoModulePath(repo *git.Repository, hash plumbing.Hash, dir string) (string, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("getting commit %s: %w", hash, err)
+	}
+
+	name := path.Join(dir, "go.mod")
+	file, err := commit.File(name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	match := goModuleRe.FindSubmatch(content)
+	if match == nil {
+		return "", fmt.Errorf("%s has no module directive", name)
+	}
+
+	modulePath := string(match[1])
+	if strings.HasPrefix(modulePath, `"`) {
+		modulePath, err = strconv.Unquote(modulePath)
+		if err != nil {
+			return "", fmt.Errorf("%s: invalid module path %s", name, match[1])
+		}
+	}
+
+	return modulePath, nil
+}
+
+// CheckGoModulePath reports whether a version can be published for a Go
+// module path. From v2 on, module paths must end in the major version
+// (example.com/mod/v2, or gopkg.in/mod.v2), and v0 and v1 modules must not
+// have a major version suffix.
+func CheckGoModulePath(modulePath string, version semver.Version) error {
+	suffix := uint64(0)
+	hasSuffix := false
+	if match := moduleMajorRe.FindStringSubmatch(modulePath); match != nil {
+		suffix, _ = strconv.ParseUint(match[1], 10, 64)
+		hasSuffix = true
+	}
+
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		if !hasSuffix || suffix != version.Major {
+			return fmt.Errorf("version %s needs a gopkg.in module path ending in .v%d, but go.mod declares %s", version, version.Major, modulePath)
+		}
+		return nil
+	}
+
+	switch {
+	case version.Major >= 2 && (!hasSuffix || suffix != version.Major):
+		return fmt.Errorf("version %s needs module path %s/v%d, but go.mod declares %s",
+			version, strings.TrimSuffix(modulePath, fmt.Sprintf("/v%d", suffix)), version.Major, modulePath)
+	case version.Major < 2 && hasSuffix && suffix >= 2:
+		return fmt.Errorf("version %s is below v%d, the major version of module path %s", version, suffix, modulePath)
+	}
+
+	return nil
+}
+
+// ModuleTagPrefix returns the tag prefix Go uses for the module in dir of a
+// multi-module repository: sdk/ for sdk, so its versions are tagged
+// sdk/v1.2.3. Major version subdirectories share their parent's prefix, so
+// sdk/v2 is tagged sdk/v2.0.0. The root module has no prefix.
+func ModuleTagPrefix(dir string) string {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if majorDirRe.MatchString(path.Base(dir)) {
+		dir = path.Dir(dir)
+	}
+	if dir == "" || dir == "." {
+		return ""
+	}
+	return dir + "/"
+}
+
+// ModuleTagPattern returns a TagPattern selecting the version tags of the
+// module in dir
+func ModuleTagPattern(dir string) string {
+	return "^" + regexp.QuoteMeta(ModuleTagPrefix(dir)) + `v\d+\.\d+\.\d+`
+}
@@ -0,0 +1,163 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoModulePath(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	addFile(t, workTree, "go.mod", "// Root module\nmodule github.com/example/mod/v2\n\ngo 1.23\n")
+	addFile(t, workTree, "sdk/go.mod", "module \"github.com/example/mod/sdk\" // quoted\n")
+	addFile(t, workTree, "broken/go.mod", "go 1.23\n")
+	hash, err := workTree.Commit("Modules", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		{"", "github.com/example/mod/v2"},
+		{"sdk", "github.com/example/mod/sdk"},
+		{"tools", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dir, func(t *testing.T) {
+			modulePath, err := GoModulePath(repo, hash, test.dir)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, modulePath)
+		})
+	}
+
+	t.Run("No module directive", func(t *testing.T) {
+		_, err := GoModulePath(repo, hash, "broken")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "broken/go.mod has no module directive")
+	})
+}
+
+func TestCheckGoModulePath(t *testing.T) {
+	tests := []struct {
+		modulePath string
+		version    string
+		expected   string
+	}{
+		{"github.com/example/mod", "0.3.0", ""},
+		{"github.com/example/mod", "1.9.0-alpha.1704164645+abcdef12", ""},
+		{"github.com/example/mod/v2", "2.0.0", ""},
+		{"github.com/example/mod/v3", "3.1.0", ""},
+		{"gopkg.in/yaml.v3", "3.0.1", ""},
+		{"github.com/example/mod", "2.0.0", "version 2.0.0 needs module path github.com/example/mod/v2, but go.mod declares github.com/example/mod"},
+		{"github.com/example/mod/v2", "3.0.0-alpha", "version 3.0.0-alpha needs module path github.com/example/mod/v3, but go.mod declares github.com/example/mod/v2"},
+		{"github.com/example/mod/v2", "1.4.0", "version 1.4.0 is below v2, the major version of module path github.com/example/mod/v2"},
+		{"gopkg.in/yaml.v2", "3.0.0", "version 3.0.0 needs a gopkg.in module path ending in .v3, but go.mod declares gopkg.in/yaml.v2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.modulePath+"@"+test.version, func(t *testing.T) {
+			err := CheckGoModulePath(test.modulePath, semver.MustParse(test.version))
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, test.expected, err.Error())
+		})
+	}
+}
+
+func TestModuleTagPrefix(t *testing.T) {
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		{"", ""},
+		{".", ""},
+		{"v2", ""},
+		{"sdk", "sdk/"},
+		{"./sdk/", "sdk/"},
+		{"sdk/v2", "sdk/"},
+		{"sdk/v1", "sdk/v1/"},
+		{"providers/aws", "providers/aws/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dir, func(t *testing.T) {
+			require.Equal(t, test.expected, ModuleTagPrefix(test.dir))
+		})
+	}
+
+	require.Equal(t, `^sdk/v\d+\.\d+\.\d+`, ModuleTagPattern("sdk/v2"))
+}
+
+func TestCalculateGoModule(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	addFile(t, workTree, "go.mod", "module github.com/example/mod\n")
+	addFile(t, workTree, "sdk/go.mod", "module github.com/example/mod/sdk/v2\n")
+	hash, err := workTree.Commit("Modules", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+	for _, tag := range []string{"v1.4.0", "sdk/v2.1.0"} {
+		_, err = repo.CreateTag(tag, hash, nil)
+		require.NoError(t, err)
+	}
+
+	t.Run("Matching module path", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			ModuleDir:  ".",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "v1.4.0", components.BaseTag)
+		require.Equal(t, "1.4.0", components.Semver.String())
+		require.Empty(t, components.Warnings)
+	})
+
+	t.Run("Module directory selects its tags", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository: repo,
+			Commitish:  plumbing.Revision("HEAD"),
+			ModuleDir:  "sdk",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "sdk/v2.1.0", components.BaseTag)
+		require.Equal(t, "2.1.0", components.Semver.String())
+		require.Empty(t, components.Warnings)
+	})
+
+	t.Run("Major version without suffix", func(t *testing.T) {
+		_, components, err := CalculateWithComponents(Options{
+			Repository:    repo,
+			Commitish:     plumbing.Revision("HEAD"),
+			ModuleDir:     ".",
+			ReleasePrefix: "2.0.0",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"version 2.0.0 needs module path github.com/example/mod/v2, but go.mod declares github.com/example/mod"}, components.Warnings)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		_, _, err := CalculateWithComponents(Options{
+			Repository:     repo,
+			Commitish:      plumbing.Revision("HEAD"),
+			ModuleDir:      ".",
+			ReleasePrefix:  "2.0.0",
+			StrictGoModule: true,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "needs module path github.com/example/mod/v2")
+	})
+}
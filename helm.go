@@ -0,0 +1,48 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Helm chart build metadata handling for Options.HelmBuildMetadata
+const (
+	// HelmBuildMetadataKeep uses the semantic version unchanged as the
+	// chart version. Helm replaces "+" with "_" when pushing to OCI
+	// registries.
+	HelmBuildMetadataKeep = "keep"
+
+	// HelmBuildMetadataStrip drops the commit hash for registries and tools
+	// that reject build metadata. Dirty builds become pre-releases
+	// (1.2.3-dirty) so they never replace a published release.
+	HelmBuildMetadataStrip = "strip"
+)
+
+func validateHelmBuildMetadata(mode string) error {
+	switch mode {
+	case "", HelmBuildMetadataKeep, HelmBuildMetadataStrip:
+		return nil
+	default:
+		return fmt.Errorf("invalid Helm build metadata mode %q (expected %s or %s)", mode, HelmBuildMetadataKeep, HelmBuildMetadataStrip)
+	}
+}
+
+// helmChartVersion formats the Chart.yaml version: the semantic version
+// without a "v" prefix, with build metadata removed in strip mode
+func helmChartVersion(version string, components *VersionComponents, opts Options) string {
+	if opts.HelmBuildMetadata != HelmBuildMetadataStrip {
+		return version
+	}
+
+	chart := fmt.Sprintf("%d.%d.%d", components.Semver.Major, components.Semver.Minor, components.Semver.Patch)
+
+	parts := prereleaseParts(components)
+	if components.Dirty {
+		parts = append(parts, "dirty")
+	}
+	if len(parts) > 0 {
+		chart += "-" + strings.Join(parts, ".")
+	}
+
+	return chart
+}
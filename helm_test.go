@@ -0,0 +1,86 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmVersions(t *testing.T) {
+	timestamp := time.Unix(1704164645, 0)
+
+	tests := []struct {
+		name       string
+		components VersionComponents
+		opts       Options
+		chart      string
+		appVersion string
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, ShortHash: "abcdef12"},
+			chart:      "1.2.3",
+			appVersion: "1.2.3",
+		},
+		{
+			name:       "Untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			chart:      "1.3.0-alpha.1704164645+abcdef12",
+			appVersion: "1.3.0-alpha.1704164645-abcdef12",
+		},
+		{
+			name:       "Untagged build stripped",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			opts:       Options{HelmBuildMetadata: HelmBuildMetadataStrip},
+			chart:      "1.3.0-alpha.1704164645",
+			appVersion: "1.3.0-alpha.1704164645-abcdef12",
+		},
+		{
+			name:       "Untagged dirty build stripped",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-rc"), Timestamp: timestamp, Dirty: true, ShortHash: "abcdef12"},
+			opts:       Options{HelmBuildMetadata: HelmBuildMetadataStrip},
+			chart:      "1.3.0-rc.1704164645.dirty",
+			appVersion: "1.3.0-rc.1704164645-abcdef12.dirty",
+		},
+		{
+			name:       "Dirty release stripped",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, Dirty: true, ShortHash: "abcdef12"},
+			opts:       Options{HelmBuildMetadata: HelmBuildMetadataStrip},
+			chart:      "1.2.3-dirty",
+			appVersion: "1.2.3-dirty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			versions, err := buildLanguageVersions(&test.components, test.opts)
+			require.NoError(t, err)
+
+			require.Equal(t, test.chart, versions.Helm)
+			require.Equal(t, test.appVersion, versions.HelmAppVersion)
+			require.Regexp(t, semverGrammarRe, versions.Helm)
+			if test.opts.HelmBuildMetadata == HelmBuildMetadataStrip {
+				require.NotContains(t, versions.Helm, "+")
+			}
+		})
+	}
+}
+
+func TestHelmVersionsFromString(t *testing.T) {
+	versions, err := CalculateFromString("v2.0.0-rc.1+abcdef12")
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0-rc.1+abcdef12", versions.Helm)
+	require.Equal(t, "2.0.0-rc.1-abcdef12", versions.HelmAppVersion)
+}
+
+func TestValidateHelmBuildMetadata(t *testing.T) {
+	require.NoError(t, validateHelmBuildMetadata(""))
+	require.NoError(t, validateHelmBuildMetadata(HelmBuildMetadataKeep))
+	require.NoError(t, validateHelmBuildMetadata(HelmBuildMetadataStrip))
+
+	err := validateHelmBuildMetadata("underscore")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid Helm build metadata mode")
+}
@@ -0,0 +1,260 @@
+package vers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Manifest kinds recognized by ManifestKind
+const (
+	ManifestPackageJSON = "package.json"
+	ManifestPyProject   = "pyproject.toml"
+	ManifestCargo       = "Cargo.toml"
+	ManifestCSProj      = "csproj"
+	ManifestHelmChart   = "Chart.yaml"
+)
+
+var (
+	jsonVersionRe    = regexp.MustCompile(`"version"\s*:\s*"([^"]*)"`)
+	tomlTableRe      = regexp.MustCompile(`^\s*\[([^\]]+)\]`)
+	tomlVersionRe    = regexp.MustCompile(`^\s*version\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	csprojVersionRe  = regexp.MustCompile(`<Version>([^<]*)</Version>`)
+	yamlVersionRe    = yamlKeyRe("version")
+	yamlAppVersionRe = yamlKeyRe("appVersion")
+)
+
+// ManifestKind identifies a project manifest from its file name, returning
+// an empty string for unsupported files
+func ManifestKind(path string) string {
+	name := filepath.Base(path)
+	switch {
+	case name == ManifestPackageJSON:
+		return ManifestPackageJSON
+	case name == ManifestPyProject:
+		return ManifestPyProject
+	case name == ManifestCargo:
+		return ManifestCargo
+	case strings.HasSuffix(name, ".csproj"):
+		return ManifestCSProj
+	case name == ManifestHelmChart:
+		return ManifestHelmChart
+	default:
+		return ""
+	}
+}
+
+// ManifestVersion returns the version a manifest should contain, using the
+// language format matching its ecosystem
+func ManifestVersion(kind string, versions *LanguageVersions) (string, error) {
+	switch kind {
+	case ManifestPackageJSON:
+		return versions.NPM, nil
+	case ManifestCargo:
+		return versions.Cargo, nil
+	case ManifestHelmChart:
+		return versions.Helm, nil
+	case ManifestPyProject:
+		return versions.Python, nil
+	case ManifestCSProj:
+		return versions.DotNet, nil
+	default:
+		return "", fmt.Errorf("unsupported manifest kind %q", kind)
+	}
+}
+
+// SetManifestVersion returns the manifest content with its version field
+// replaced, leaving all other formatting and comments untouched
+func SetManifestVersion(kind string, content []byte, version string) ([]byte, error) {
+	var start, end int
+	var err error
+
+	switch kind {
+	case ManifestPackageJSON:
+		start, end, err = findJSONVersion(content)
+	case ManifestPyProject:
+		start, end, err = findTOMLVersion(content, "project", "tool.poetry")
+	case ManifestCargo:
+		start, end, err = findTOMLVersion(content, "package", "workspace.package")
+	case ManifestCSProj:
+		start, end, err = findSubmatch(csprojVersionRe, content)
+	case ManifestHelmChart:
+		start, end, err = findSubmatch(yamlVersionRe, content)
+	default:
+		return nil, fmt.Errorf("unsupported manifest kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]byte, 0, len(content)+len(version))
+	updated = append(updated, content[:start]...)
+	updated = append(updated, version...)
+	updated = append(updated, content[end:]...)
+
+	return updated, nil
+}
+
+// UpdateManifest sets the version field of the manifest at path, returning
+// whether its content changed. When check is set the file is not written,
+// so a true result means the file is out of date.
+func UpdateManifest(path string, versions *LanguageVersions, check bool) (bool, error) {
+	kind := ManifestKind(path)
+	if kind == "" {
+		return false, fmt.Errorf("%s: unsupported manifest (expected package.json, pyproject.toml, Cargo.toml, *.csproj or Chart.yaml)", path)
+	}
+
+	version, err := ManifestVersion(kind, versions)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := SetManifestVersion(kind, content, version)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if kind == ManifestHelmChart {
+		updated, err = SetHelmAppVersion(updated, versions.HelmAppVersion)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if bytes.Equal(content, updated) {
+		return false, nil
+	}
+
+	if check {
+		return true, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(path, updated, info.Mode().Perm())
+}
+
+// SetHelmAppVersion returns Chart.yaml content with its appVersion replaced.
+// Charts without an appVersion get one, quoted as Helm recommends, on the
+// line after the chart version.
+func SetHelmAppVersion(content []byte, appVersion string) ([]byte, error) {
+	start, end, err := findSubmatch(yamlAppVersionRe, content)
+	if err == nil {
+		updated := make([]byte, 0, len(content)+len(appVersion))
+		updated = append(updated, content[:start]...)
+		updated = append(updated, appVersion...)
+		return append(updated, content[end:]...), nil
+	}
+
+	match := yamlVersionRe.FindIndex(content)
+	if match == nil {
+		return nil, fmt.Errorf("no version field found")
+	}
+
+	lineEnd := len(content)
+	if i := bytes.IndexByte(content[match[1]:], '\n'); i >= 0 {
+		lineEnd = match[1] + i + 1
+	}
+
+	line := fmt.Sprintf("appVersion: %q\n", appVersion)
+	if lineEnd == len(content) && !bytes.HasSuffix(content, []byte("\n")) {
+		line = "\n" + line
+	}
+
+	updated := make([]byte, 0, len(content)+len(line))
+	updated = append(updated, content[:lineEnd]...)
+	updated = append(updated, line...)
+	return append(updated, content[lineEnd:]...), nil
+}
+
+// yamlKeyRe matches a top-level YAML scalar, capturing its double-quoted,
+// single-quoted or plain value
+func yamlKeyRe(key string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^` + key + `:[ \t]*(?:"([^"]*)"|'([^']*)'|([^\s#"']+))`)
+}
+
+// findJSONVersion locates the value of the top-level "version" key
+func findJSONVersion(content []byte) (int, int, error) {
+	for _, match := range jsonVersionRe.FindAllSubmatchIndex(content, -1) {
+		if jsonDepth(content[:match[0]]) == 1 {
+			return match[2], match[3], nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no top-level version field found")
+}
+
+// jsonDepth returns the object/array nesting depth at the end of content
+func jsonDepth(content []byte) int {
+	depth := 0
+	inString, escaped := false, false
+
+	for _, c := range content {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return depth
+}
+
+// findTOMLVersion locates the value of the version key in the first of the
+// given tables that defines one
+func findTOMLVersion(content []byte, tables ...string) (int, int, error) {
+	for _, table := range tables {
+		current, offset := "", 0
+
+		for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+			if match := tomlTableRe.FindSubmatch(line); match != nil {
+				current = strings.TrimSpace(string(match[1]))
+			} else if current == table {
+				if match := tomlVersionRe.FindSubmatchIndex(line); match != nil {
+					// Either the double- or single-quoted group matched
+					if match[2] >= 0 {
+						return offset + match[2], offset + match[3], nil
+					}
+					return offset + match[4], offset + match[5], nil
+				}
+			}
+			offset += len(line)
+		}
+	}
+
+	return 0, 0, fmt.Errorf("no version field found in [%s]", strings.Join(tables, "] or ["))
+}
+
+// findSubmatch locates the first non-empty capture group of re
+func findSubmatch(re *regexp.Regexp, content []byte) (int, int, error) {
+	match := re.FindSubmatchIndex(content)
+	if match == nil {
+		return 0, 0, fmt.Errorf("no version field found")
+	}
+
+	for i := 2; i < len(match); i += 2 {
+		if match[i] >= 0 {
+			return match[i], match[i+1], nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("no version field found")
+}
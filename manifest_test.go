@@ -0,0 +1,274 @@
+package vers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestKind(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"package.json", ManifestPackageJSON},
+		{"web/package.json", ManifestPackageJSON},
+		{"pyproject.toml", ManifestPyProject},
+		{"crates/core/Cargo.toml", ManifestCargo},
+		{"src/App/App.csproj", ManifestCSProj},
+		{"charts/app/Chart.yaml", ManifestHelmChart},
+		{"setup.py", ""},
+		{"values.yaml", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			require.Equal(t, test.expected, ManifestKind(test.path))
+		})
+	}
+}
+
+func TestSetManifestVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		content  string
+		expected string
+	}{
+		{
+			name: "package.json top-level version",
+			kind: ManifestPackageJSON,
+			content: `{
+  "name": "app",
+  "engines": { "version": "keep" },
+  "version": "0.0.0",
+  "dependencies": {}
+}
+`,
+			expected: `{
+  "name": "app",
+  "engines": { "version": "keep" },
+  "version": "1.2.3",
+  "dependencies": {}
+}
+`,
+		},
+		{
+			name: "pyproject.toml project table",
+			kind: ManifestPyProject,
+			content: `[build-system]
+version = "keep"
+
+[project]
+name = "app"
+version = "0.1.0"  # managed by vers
+`,
+			expected: `[build-system]
+version = "keep"
+
+[project]
+name = "app"
+version = "1.2.3"  # managed by vers
+`,
+		},
+		{
+			name: "pyproject.toml poetry table",
+			kind: ManifestPyProject,
+			content: `[tool.poetry]
+name = "app"
+version = '0.1.0'
+`,
+			expected: `[tool.poetry]
+name = "app"
+version = '1.2.3'
+`,
+		},
+		{
+			name: "Cargo.toml package table",
+			kind: ManifestCargo,
+			content: `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0" }
+`,
+			expected: `[package]
+name = "app"
+version = "1.2.3"
+
+[dependencies]
+serde = { version = "1.0" }
+`,
+		},
+		{
+			name: "csproj Version element",
+			kind: ManifestCSProj,
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <Version>0.1.0</Version>
+  </PropertyGroup>
+</Project>
+`,
+			expected: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <Version>1.2.3</Version>
+  </PropertyGroup>
+</Project>
+`,
+		},
+		{
+			name: "Chart.yaml version",
+			kind: ManifestHelmChart,
+			content: `apiVersion: v2
+name: app
+version: 0.1.0 # chart version
+appVersion: "0.1.0"
+`,
+			expected: `apiVersion: v2
+name: app
+version: 1.2.3 # chart version
+appVersion: "0.1.0"
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updated, err := SetManifestVersion(test.kind, []byte(test.content), "1.2.3")
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(updated))
+		})
+	}
+
+	t.Run("Missing version field", func(t *testing.T) {
+		_, err := SetManifestVersion(ManifestPyProject, []byte("[project]\ndynamic = [\"version\"]\n"), "1.2.3")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no version field found")
+	})
+}
+
+func TestSetHelmAppVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Quoted appVersion",
+			content:  "version: 0.1.0\nappVersion: \"0.1.0\" # image tag\n",
+			expected: "version: 0.1.0\nappVersion: \"1.2.3-abcdef12\" # image tag\n",
+		},
+		{
+			name:     "Plain appVersion",
+			content:  "appVersion: 0.1.0\nversion: 0.1.0\n",
+			expected: "appVersion: 1.2.3-abcdef12\nversion: 0.1.0\n",
+		},
+		{
+			name:     "Missing appVersion",
+			content:  "name: app\nversion: 0.1.0\ntype: application\n",
+			expected: "name: app\nversion: 0.1.0\nappVersion: \"1.2.3-abcdef12\"\ntype: application\n",
+		},
+		{
+			name:     "Missing appVersion without trailing newline",
+			content:  "name: app\nversion: 0.1.0",
+			expected: "name: app\nversion: 0.1.0\nappVersion: \"1.2.3-abcdef12\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updated, err := SetHelmAppVersion([]byte(test.content), "1.2.3-abcdef12")
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(updated))
+		})
+	}
+
+	t.Run("Missing version field", func(t *testing.T) {
+		_, err := SetHelmAppVersion([]byte("name: app\n"), "1.2.3")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no version field found")
+	})
+}
+
+func TestUpdateManifest(t *testing.T) {
+	versions := &LanguageVersions{
+		SemVer:         "1.2.3-alpha.1+abcdef12",
+		Python:         "1.2.3a1",
+		JavaScript:     "v1.2.3-alpha.1",
+		DotNet:         "1.2.3-alpha.1",
+		Go:             "v1.2.3-alpha.1",
+		NPM:            "1.2.3-alpha.1",
+		Cargo:          "1.2.3-alpha.1",
+		Helm:           "1.2.3-alpha.1",
+		HelmAppVersion: "1.2.3-alpha.1-abcdef12",
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pyproject.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[project]\nversion = \"0.1.0\"\n"), 0o644))
+
+	t.Run("Check reports out of date file", func(t *testing.T) {
+		changed, err := UpdateManifest(path, versions, true)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "[project]\nversion = \"0.1.0\"\n", string(content))
+	})
+
+	t.Run("Write uses ecosystem format", func(t *testing.T) {
+		changed, err := UpdateManifest(path, versions, false)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "[project]\nversion = \"1.2.3a1\"\n", string(content))
+	})
+
+	t.Run("Check passes once written", func(t *testing.T) {
+		changed, err := UpdateManifest(path, versions, true)
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("Cargo format", func(t *testing.T) {
+		cargo := filepath.Join(dir, "Cargo.toml")
+		require.NoError(t, os.WriteFile(cargo, []byte("[package]\nversion = \"0.1.0\"\n"), 0o644))
+
+		changed, err := UpdateManifest(cargo, versions, false)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		content, err := os.ReadFile(cargo)
+		require.NoError(t, err)
+		require.Equal(t, "[package]\nversion = \"1.2.3-alpha.1\"\n", string(content))
+	})
+
+	t.Run("Helm chart and app versions", func(t *testing.T) {
+		chart := filepath.Join(dir, "Chart.yaml")
+		require.NoError(t, os.WriteFile(chart, []byte("apiVersion: v2\nversion: 0.1.0\nappVersion: \"0.1.0\"\n"), 0o644))
+
+		changed, err := UpdateManifest(chart, versions, false)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		content, err := os.ReadFile(chart)
+		require.NoError(t, err)
+		require.Equal(t, "apiVersion: v2\nversion: 1.2.3-alpha.1\nappVersion: \"1.2.3-alpha.1-abcdef12\"\n", string(content))
+
+		changed, err = UpdateManifest(chart, versions, true)
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("Unsupported file", func(t *testing.T) {
+		_, err := UpdateManifest(filepath.Join(dir, "setup.py"), versions, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported manifest")
+	})
+}
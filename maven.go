@@ -0,0 +1,100 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Maven snapshot styles for Options.MavenSnapshot
+const (
+	// MavenSnapshotPlain formats untagged builds as 1.3.0-SNAPSHOT
+	MavenSnapshotPlain = "snapshot"
+
+	// MavenSnapshotTimestamp formats untagged builds in Maven's unique
+	// snapshot form, 1.3.0-20240102.030405-7, where the final number is the
+	// build number or the distance from the base tag. Repositories resolve
+	// it as a build of 1.3.0-SNAPSHOT, but it has no qualifier, so Maven's
+	// ComparableVersion orders it after the 1.3.0 release.
+	MavenSnapshotTimestamp = "timestamp"
+)
+
+const mavenSnapshotQualifier = "SNAPSHOT"
+
+// mavenQualifiers maps pre-release labels onto qualifiers Maven's
+// ComparableVersion orders alpha < beta < rc < SNAPSHOT < release. This
+// holds for plain snapshots only; timestamped snapshots sort after the
+// release. Maven has no qualifier ordered before alpha, so dev builds are
+// always snapshots.
+var mavenQualifiers = map[string]string{
+	"alpha": "alpha",
+	"beta":  "beta",
+	"rc":    "rc",
+}
+
+func validateMavenSnapshot(style string) error {
+	switch style {
+	case "", MavenSnapshotPlain, MavenSnapshotTimestamp:
+		return nil
+	default:
+		return fmt.Errorf("invalid Maven snapshot style %q (expected %s or %s)", style, MavenSnapshotPlain, MavenSnapshotTimestamp)
+	}
+}
+
+// mavenVersion formats the calculated version for Maven and Gradle. Exact
+// releases keep their version, exact pre-release tags use Maven qualifiers
+// (1.2.0-rc1) and everything else, including dirty trees, is a snapshot.
+func mavenVersion(components *VersionComponents, style string) string {
+	base := fmt.Sprintf("%d.%d.%d", components.Semver.Major, components.Semver.Minor, components.Semver.Patch)
+
+	if components.verbatim() && !components.Dirty {
+		if len(components.Semver.Pre) == 0 {
+			return base
+		}
+		if qualifier, ok := mavenQualifiers[components.Semver.Pre[0].VersionStr]; ok {
+			return base + "-" + qualifier + mavenQualifierNumber(components.Semver.Pre)
+		}
+	}
+
+	if style == MavenSnapshotTimestamp {
+		number := components.BuildNumber
+		if number == "" {
+			// Maven build numbers start at 1
+			number = fmt.Sprint(max(components.Distance, 1))
+		}
+		return fmt.Sprintf("%s-%s-%s", base, components.Timestamp.UTC().Format("20060102.150405"), number)
+	}
+
+	return base + "-" + mavenSnapshotQualifier
+}
+
+// mavenQualifierNumber joins the pre-release parts after the label, so
+// rc.1 becomes rc1
+func mavenQualifierNumber(pre []semver.PRVersion) string {
+	parts := make([]string, 0, len(pre)-1)
+	for _, part := range pre[1:] {
+		parts = append(parts, part.String())
+	}
+	return strings.Join(parts, ".")
+}
+
+// mavenFromString converts an existing version string for Maven, treating
+// it as exact. Unrecognised pre-release labels are kept as they are, and
+// build metadata has no Maven equivalent so it is dropped.
+func mavenFromString(version string) string {
+	base, _, _ := strings.Cut(version, "+")
+
+	parsed, err := semver.Parse(base)
+	if err != nil {
+		return base
+	}
+
+	if len(parsed.Pre) > 0 && parsed.Pre[0].VersionStr != "dev" {
+		if _, ok := mavenQualifiers[parsed.Pre[0].VersionStr]; !ok {
+			return base
+		}
+	}
+
+	return mavenVersion(&VersionComponents{Semver: parsed, IsExact: true}, MavenSnapshotPlain)
+}
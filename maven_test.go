@@ -0,0 +1,376 @@
+package vers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMavenVersion(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		components VersionComponents
+		style      string
+		expected   string
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0"), IsExact: true},
+			expected:   "1.2.0",
+		},
+		{
+			name:       "Release candidate tag",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0-rc.1"), IsExact: true},
+			expected:   "1.2.0-rc1",
+		},
+		{
+			name:       "Beta tag without number",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0-beta"), IsExact: true},
+			expected:   "1.2.0-beta",
+		},
+		{
+			name:       "Dev tag",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0-dev.3"), IsExact: true},
+			expected:   "1.2.0-SNAPSHOT",
+		},
+		{
+			name:       "Dirty release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0"), IsExact: true, Dirty: true},
+			expected:   "1.2.0-SNAPSHOT",
+		},
+		{
+			name:       "Untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, Distance: 7},
+			expected:   "1.3.0-SNAPSHOT",
+		},
+		{
+			name:       "Timestamped snapshot",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, Distance: 7},
+			style:      MavenSnapshotTimestamp,
+			expected:   "1.3.0-20240102.030405-7",
+		},
+		{
+			name:       "Timestamped snapshot with build number",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, Distance: 7, BuildNumber: "42"},
+			style:      MavenSnapshotTimestamp,
+			expected:   "1.3.0-20240102.030405-42",
+		},
+		{
+			name:       "Timestamped dirty release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.0"), Timestamp: timestamp, IsExact: true, Dirty: true},
+			style:      MavenSnapshotTimestamp,
+			expected:   "1.2.0-20240102.030405-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, mavenVersion(&test.components, test.style))
+		})
+	}
+}
+
+func TestMavenFromString(t *testing.T) {
+	require.Equal(t, "1.2.3", mavenFromString("1.2.3"))
+	require.Equal(t, "1.2.3-rc1", mavenFromString("1.2.3-rc.1+abcdef12"))
+	require.Equal(t, "1.2.3-SNAPSHOT", mavenFromString("1.2.3-dev.5"))
+	require.Equal(t, "1.2.3-alpha01", mavenFromString("1.2.3-alpha01+dirty"))
+	require.Equal(t, "2.0.0-preview.1", mavenFromString("2.0.0-preview.1"))
+}
+
+func TestCalculateMaven(t *testing.T) {
+	t.Run("Release", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3", version.Maven)
+	})
+
+	t.Run("Commits past tag", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3-4-g0123456", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0-SNAPSHOT", version.Maven)
+	})
+
+	t.Run("Timestamped snapshot", func(t *testing.T) {
+		version, err := Calculate(Options{
+			Archival:      testArchival("v1.2.3-4-g0123456", ""),
+			MavenSnapshot: MavenSnapshotTimestamp,
+		})
+		require.NoError(t, err)
+		require.Regexp(t, `^1\.3\.0-\d{8}\.\d{6}-4$`, version.Maven)
+	})
+
+	t.Run("Invalid snapshot style", func(t *testing.T) {
+		_, err := Calculate(Options{Archival: testArchival("v1.2.3", ""), MavenSnapshot: "unique"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid Maven snapshot style")
+	})
+}
+
+// mavenItem is a parsed item of a Maven version: a number, a qualifier or
+// a list started by "-" or a number/qualifier transition
+type mavenItem struct {
+	kind  int
+	num   uint64
+	str   string
+	items []*mavenItem
+}
+
+const (
+	mavenIntItem = iota
+	mavenStringItem
+	mavenListItem
+)
+
+// mavenQualifierOrder is the order of well-known qualifiers in Maven's
+// ComparableVersion, "" being the release
+var mavenQualifierOrder = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+// mavenCompare compares two versions following the rules of Maven's
+// ComparableVersion, returning -1, 0 or 1
+func mavenCompare(a, b string) int {
+	return sign(compareMavenItems(parseMavenVersion(a), parseMavenVersion(b)))
+}
+
+func parseMavenVersion(version string) *mavenItem {
+	version = strings.ToLower(version)
+
+	root := &mavenItem{kind: mavenListItem}
+	list := root
+	stack := []*mavenItem{root}
+
+	startList := func() {
+		sub := &mavenItem{kind: mavenListItem}
+		list.items = append(list.items, sub)
+		list = sub
+		stack = append(stack, sub)
+	}
+
+	isDigit := false
+	start := 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				list.items = append(list.items, &mavenItem{kind: mavenIntItem})
+			} else {
+				list.items = append(list.items, newMavenItem(isDigit, version[start:i], false))
+			}
+			start = i + 1
+			if c == '-' {
+				startList()
+			}
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				list.items = append(list.items, newMavenItem(false, version[start:i], true))
+				start = i
+				startList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				list.items = append(list.items, newMavenItem(true, version[start:i], false))
+				start = i
+				startList()
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		list.items = append(list.items, newMavenItem(isDigit, version[start:], false))
+	}
+
+	// Trailing zeros and release qualifiers are dropped from each list
+	for i := len(stack) - 1; i >= 0; i-- {
+		items := stack[i].items
+		for j := len(items) - 1; j >= 0; j-- {
+			if items[j].isNull() {
+				items = append(items[:j], items[j+1:]...)
+			} else if items[j].kind != mavenListItem {
+				break
+			}
+		}
+		stack[i].items = items
+	}
+
+	return root
+}
+
+func newMavenItem(isDigit bool, value string, followedByDigit bool) *mavenItem {
+	if isDigit {
+		n, _ := strconv.ParseUint(value, 10, 64)
+		return &mavenItem{kind: mavenIntItem, num: n}
+	}
+
+	if followedByDigit && len(value) == 1 {
+		switch value {
+		case "a":
+			value = "alpha"
+		case "b":
+			value = "beta"
+		case "m":
+			value = "milestone"
+		}
+	}
+	switch value {
+	case "ga", "final", "release":
+		value = ""
+	case "cr":
+		value = "rc"
+	}
+
+	return &mavenItem{kind: mavenStringItem, str: value}
+}
+
+func (i *mavenItem) isNull() bool {
+	switch i.kind {
+	case mavenIntItem:
+		return i.num == 0
+	case mavenStringItem:
+		return i.str == ""
+	default:
+		return len(i.items) == 0
+	}
+}
+
+func mavenComparableQualifier(qualifier string) string {
+	for i, known := range mavenQualifierOrder {
+		if qualifier == known {
+			return strconv.Itoa(i)
+		}
+	}
+	return fmt.Sprintf("%d-%s", len(mavenQualifierOrder), qualifier)
+}
+
+// compareMavenItems compares a with b, which is nil past the end of a list
+func compareMavenItems(a, b *mavenItem) int {
+	switch a.kind {
+	case mavenIntItem:
+		switch {
+		case b == nil && a.num == 0:
+			return 0
+		case b == nil:
+			return 1
+		case b.kind == mavenIntItem:
+			return compareUint(a.num, b.num)
+		default:
+			return 1
+		}
+
+	case mavenStringItem:
+		switch {
+		case b == nil:
+			return strings.Compare(mavenComparableQualifier(a.str), mavenComparableQualifier(""))
+		case b.kind == mavenStringItem:
+			return strings.Compare(mavenComparableQualifier(a.str), mavenComparableQualifier(b.str))
+		default:
+			return -1
+		}
+	}
+
+	switch {
+	case b == nil:
+		for _, item := range a.items {
+			if result := compareMavenItems(item, nil); result != 0 {
+				return result
+			}
+		}
+		return 0
+	case b.kind == mavenIntItem:
+		return -1
+	case b.kind == mavenStringItem:
+		return 1
+	}
+
+	for i := 0; i < max(len(a.items), len(b.items)); i++ {
+		var left, right *mavenItem
+		if i < len(a.items) {
+			left = a.items[i]
+		}
+		if i < len(b.items) {
+			right = b.items[i]
+		}
+
+		var result int
+		switch {
+		case left == nil && right == nil:
+			result = 0
+		case left == nil:
+			result = -compareMavenItems(right, nil)
+		default:
+			result = compareMavenItems(left, right)
+		}
+		if result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func TestMavenCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1", 0},
+		{"1.0.0-ga", "1", 0},
+		{"1a1", "1-alpha-1", 0},
+		{"1-alpha", "1-beta", -1},
+		{"1-rc", "1-SNAPSHOT", -1},
+		{"1-SNAPSHOT", "1", -1},
+		{"1", "1-sp", -1},
+		{"1.2", "1.10", -1},
+		{"1-1", "1.1", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			require.Equal(t, test.expected, mavenCompare(test.a, test.b))
+			require.Equal(t, -test.expected, mavenCompare(test.b, test.a))
+		})
+	}
+}
+
+func TestMavenVersionOrdering(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	exact := func(version string) string {
+		return mavenVersion(&VersionComponents{Semver: semver.MustParse(version), IsExact: true}, MavenSnapshotPlain)
+	}
+	untagged := func(version, style string, distance int) string {
+		return mavenVersion(&VersionComponents{Semver: semver.MustParse(version), Timestamp: timestamp, Distance: distance}, style)
+	}
+
+	t.Run("Snapshots", func(t *testing.T) {
+		ordered := []string{
+			exact("1.3.0-alpha.1"),
+			exact("1.3.0-beta.1"),
+			exact("1.3.0-rc.1"),
+			untagged("1.3.0-alpha", MavenSnapshotPlain, 1),
+			exact("1.3.0"),
+			untagged("1.3.1-alpha", MavenSnapshotPlain, 1),
+		}
+		for i := 1; i < len(ordered); i++ {
+			require.Equal(t, -1, mavenCompare(ordered[i-1], ordered[i]), "%s < %s", ordered[i-1], ordered[i])
+		}
+	})
+
+	// Timestamped snapshots have no qualifier, so ComparableVersion orders
+	// them after the release they lead up to
+	t.Run("Timestamped snapshots", func(t *testing.T) {
+		first := untagged("1.3.0-alpha", MavenSnapshotTimestamp, 7)
+		second := untagged("1.3.0-alpha", MavenSnapshotTimestamp, 8)
+
+		require.Equal(t, -1, mavenCompare(first, second))
+		require.Equal(t, 1, mavenCompare(first, exact("1.3.0")))
+		require.Equal(t, -1, mavenCompare(second, exact("1.3.1-alpha.1")))
+	})
+}
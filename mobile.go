@@ -0,0 +1,183 @@
+package vers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// DefaultVersionCodeLayout encodes two digits each of major, minor and
+// patch, one pre-release stage digit and two digits of pre-release number,
+// e.g. 1.3.0-rc.2 is 10300302 and 1.3.0 is 10300900
+const DefaultVersionCodeLayout = "MMmmppsnn"
+
+// MaxAndroidVersionCode is the largest versionCode Google Play accepts
+const MaxAndroidVersionCode = 2100000000
+
+// versionCodeStages orders pre-release stages below releases in the stage
+// digits of a version code
+var versionCodeStages = map[string]uint64{
+	"dev":   0,
+	"alpha": 1,
+	"beta":  2,
+	"rc":    3,
+}
+
+// versionCodeReleaseStage is the stage of releases, above every pre-release
+const versionCodeReleaseStage = 9
+
+// MobileVersions are the version numbers of a mobile app build
+type MobileVersions struct {
+	// AndroidVersionCode is the integer versionCode for build.gradle
+	AndroidVersionCode int `json:"android_version_code"`
+
+	// AppleShortVersion is the CFBundleShortVersionString marketing version
+	AppleShortVersion string `json:"apple_short_version"`
+
+	// AppleBundleVersion is the CFBundleVersion build number, the same
+	// increasing integer as AndroidVersionCode
+	AppleBundleVersion string `json:"apple_bundle_version"`
+}
+
+// versionCodeField is a run of layout digits holding one value
+type versionCodeField struct {
+	letter byte
+	width  int
+}
+
+// NewMobileVersions encodes a version into mobile build numbers using a
+// digit layout of M (major), m (minor), p (patch), s (pre-release stage)
+// and n (pre-release number) runs, e.g. DefaultVersionCodeLayout. The
+// pre-release number is the tag's number for exact tags, otherwise the
+// build number or the distance from the base tag. Components may be nil for
+// converted versions, which are treated as exact. An empty layout uses
+// DefaultVersionCodeLayout. Values that do not fit their digits, or codes
+// above MaxAndroidVersionCode, are errors.
+func NewMobileVersions(versions *LanguageVersions, components *VersionComponents, layout string) (*MobileVersions, error) {
+	if layout == "" {
+		layout = DefaultVersionCodeLayout
+	}
+
+	fields, err := parseVersionCodeLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if components == nil {
+		parsed, err := semver.Parse(versions.SemVer)
+		if err != nil {
+			return nil, fmt.Errorf("parsing version %q: %w", versions.SemVer, err)
+		}
+		components = &VersionComponents{Semver: parsed, IsExact: true}
+	}
+
+	values, err := versionCodeValues(components)
+	if err != nil {
+		return nil, err
+	}
+
+	var code uint64
+	for _, field := range fields {
+		limit := pow10(field.width)
+		value := values[field.letter]
+		if value >= limit {
+			return nil, fmt.Errorf("%s %d does not fit in %d digit(s) of version code layout %q",
+				versionCodeFieldNames[field.letter], value, field.width, layout)
+		}
+		code = code*limit + value
+	}
+
+	if code > MaxAndroidVersionCode {
+		return nil, fmt.Errorf("version code %d exceeds the maximum of %d", code, MaxAndroidVersionCode)
+	}
+
+	return &MobileVersions{
+		AndroidVersionCode: int(code),
+		AppleShortVersion:  fmt.Sprintf("%d.%d.%d", components.Semver.Major, components.Semver.Minor, components.Semver.Patch),
+		AppleBundleVersion: strconv.FormatUint(code, 10),
+	}, nil
+}
+
+var versionCodeFieldNames = map[byte]string{
+	'M': "major",
+	'm': "minor",
+	'p': "patch",
+	's': "pre-release stage",
+	'n': "pre-release number",
+}
+
+func parseVersionCodeLayout(layout string) ([]versionCodeField, error) {
+	var fields []versionCodeField
+	seen := make(map[byte]bool)
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if _, ok := versionCodeFieldNames[c]; !ok {
+			return nil, fmt.Errorf("invalid version code layout %q: unknown field %q (expected M, m, p, s or n)", layout, c)
+		}
+
+		if len(fields) > 0 && fields[len(fields)-1].letter == c {
+			fields[len(fields)-1].width++
+			continue
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("invalid version code layout %q: %s digits must be contiguous", layout, versionCodeFieldNames[c])
+		}
+		seen[c] = true
+		fields = append(fields, versionCodeField{letter: c, width: 1})
+	}
+
+	// Ten digits already exceed MaxAndroidVersionCode above 2100000000
+	if len(layout) > len(strconv.Itoa(MaxAndroidVersionCode)) {
+		return nil, fmt.Errorf("invalid version code layout %q: at most %d digits fit in a version code", layout, len(strconv.Itoa(MaxAndroidVersionCode)))
+	}
+
+	return fields, nil
+}
+
+// versionCodeValues returns the value of each layout field
+func versionCodeValues(components *VersionComponents) (map[byte]uint64, error) {
+	values := map[byte]uint64{
+		'M': components.Semver.Major,
+		'm': components.Semver.Minor,
+		'p': components.Semver.Patch,
+		's': versionCodeReleaseStage,
+	}
+
+	if len(components.Semver.Pre) == 0 {
+		return values, nil
+	}
+
+	label := components.Semver.Pre[0].VersionStr
+	stage, ok := versionCodeStages[label]
+	if !ok {
+		return nil, fmt.Errorf("pre-release %q has no version code stage (expected %s)", label, strings.Join(prereleaseLabels, ", "))
+	}
+	values['s'] = stage
+
+	switch {
+	case components.verbatim() && len(components.Semver.Pre) > 1:
+		values['n'] = components.Semver.Pre[1].VersionNum
+	case components.verbatim():
+		values['n'] = 0
+	case components.BuildNumber != "":
+		n, err := strconv.ParseUint(components.BuildNumber, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("build number %q is not numeric: %w", components.BuildNumber, err)
+		}
+		values['n'] = n
+	default:
+		values['n'] = uint64(components.Distance)
+	}
+
+	return values, nil
+}
+
+func pow10(n int) uint64 {
+	result := uint64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
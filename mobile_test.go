@@ -0,0 +1,135 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMobileVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		components VersionComponents
+		layout     string
+		expected   int
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0"), IsExact: true},
+			expected:   10300900,
+		},
+		{
+			name:       "Release candidate tag",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-rc.2"), IsExact: true},
+			expected:   10300302,
+		},
+		{
+			name:       "Untagged build uses distance",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Distance: 7},
+			expected:   10300107,
+		},
+		{
+			name:       "Untagged build uses build number",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Distance: 7, BuildNumber: "42"},
+			expected:   10300142,
+		},
+		{
+			name:       "Custom layout",
+			components: VersionComponents{Semver: semver.MustParse("12.34.5"), IsExact: true},
+			layout:     "MMMmmmpps",
+			expected:   12034059,
+		},
+		{
+			name:       "Layout without pre-release fields",
+			components: VersionComponents{Semver: semver.MustParse("2.1.0-beta"), Distance: 3},
+			layout:     "Mmmpp",
+			expected:   20100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mobile, err := NewMobileVersions(&LanguageVersions{}, &test.components, test.layout)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, mobile.AndroidVersionCode)
+		})
+	}
+
+	t.Run("Apple versions", func(t *testing.T) {
+		mobile, err := NewMobileVersions(&LanguageVersions{}, &VersionComponents{
+			Semver:   semver.MustParse("1.3.0-alpha"),
+			Distance: 7,
+		}, "")
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0", mobile.AppleShortVersion)
+		require.Equal(t, "10300107", mobile.AppleBundleVersion)
+	})
+
+	t.Run("Converted version", func(t *testing.T) {
+		converted, err := CalculateFromString("v2.0.0-beta.1")
+		require.NoError(t, err)
+
+		mobile, err := NewMobileVersions(converted, nil, "")
+		require.NoError(t, err)
+		require.Equal(t, 20000201, mobile.AndroidVersionCode)
+	})
+
+	t.Run("Monotonic across a release cycle", func(t *testing.T) {
+		ordered := []VersionComponents{
+			{Semver: semver.MustParse("1.2.0"), IsExact: true},
+			{Semver: semver.MustParse("1.3.0-alpha"), Distance: 1},
+			{Semver: semver.MustParse("1.3.0-alpha"), Distance: 2},
+			{Semver: semver.MustParse("1.3.0-beta.1"), IsExact: true},
+			{Semver: semver.MustParse("1.3.0-rc.1"), IsExact: true},
+			{Semver: semver.MustParse("1.3.0-rc.2"), IsExact: true},
+			{Semver: semver.MustParse("1.3.0"), IsExact: true},
+			{Semver: semver.MustParse("1.3.1-alpha"), Distance: 1},
+			{Semver: semver.MustParse("2.0.0"), IsExact: true},
+		}
+
+		previous := 0
+		for _, components := range ordered {
+			mobile, err := NewMobileVersions(&LanguageVersions{}, &components, "")
+			require.NoError(t, err)
+			require.Greater(t, mobile.AndroidVersionCode, previous, components.Semver.String())
+			previous = mobile.AndroidVersionCode
+		}
+	})
+
+	t.Run("Field overflow", func(t *testing.T) {
+		_, err := NewMobileVersions(&LanguageVersions{}, &VersionComponents{
+			Semver:   semver.MustParse("1.3.0-alpha"),
+			Distance: 100,
+		}, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `pre-release number 100 does not fit in 2 digit(s) of version code layout "MMmmppsnn"`)
+	})
+
+	t.Run("Code overflow", func(t *testing.T) {
+		_, err := NewMobileVersions(&LanguageVersions{}, &VersionComponents{
+			Semver:  semver.MustParse("22.0.0"),
+			IsExact: true,
+		}, "MMmmmppppp")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds the maximum")
+	})
+}
+
+func TestParseVersionCodeLayout(t *testing.T) {
+	fields, err := parseVersionCodeLayout("MMmmppsnn")
+	require.NoError(t, err)
+	require.Equal(t, []versionCodeField{{'M', 2}, {'m', 2}, {'p', 2}, {'s', 1}, {'n', 2}}, fields)
+
+	_, err = parseVersionCodeLayout("MMxpp")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown field")
+
+	_, err = parseVersionCodeLayout("MmMpp")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must be contiguous")
+
+	_, err = parseVersionCodeLayout("MMMmmmpppsn")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "at most 10 digits")
+}
@@ -0,0 +1,189 @@
+package vers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Output is a named value exported to CI systems and shells
+type Output struct {
+	Name  string
+	Value string
+}
+
+// Outputs flattens the calculated versions and their components into named
+// values. Components may be nil, e.g. for fallback versions, in which case
+// only the version strings are returned.
+func Outputs(versions *LanguageVersions, components *VersionComponents) []Output {
+	outputs := []Output{
+		{Name: "semver", Value: versions.SemVer},
+		{Name: "python", Value: versions.Python},
+		{Name: "javascript", Value: versions.JavaScript},
+		{Name: "dotnet", Value: versions.DotNet},
+		{Name: "go", Value: versions.Go},
+		{Name: "maven", Value: versions.Maven},
+		{Name: "debian", Value: versions.Debian},
+		{Name: "rpm", Value: versions.RPM},
+		{Name: "rpm-release", Value: versions.RPMRelease},
+		{Name: "docker", Value: versions.Docker},
+		{Name: "npm", Value: versions.NPM},
+		{Name: "rubygems", Value: versions.RubyGems},
+		{Name: "cargo", Value: versions.Cargo},
+		{Name: "helm", Value: versions.Helm},
+		{Name: "helm-app-version", Value: versions.HelmAppVersion},
+		{Name: "dotnet-assembly", Value: versions.DotNetAssembly},
+		{Name: "dotnet-file", Value: versions.DotNetFile},
+		{Name: "dotnet-informational", Value: versions.DotNetInformational},
+		{Name: "nuget-v1", Value: versions.NuGetV1},
+	}
+
+	if components == nil {
+		return outputs
+	}
+
+	isPrerelease := len(components.Semver.Pre) > 0
+	prerelease := ""
+	if isPrerelease {
+		prerelease = components.Semver.Pre[0].String()
+	}
+
+	// Tags are comma-separated, which docker/build-push-action accepts and
+	// which stays on one line for the env and make formats
+	dockerTags, _ := DockerTags(versions, components, false)
+
+	return append(outputs,
+		Output{Name: "docker-tags", Value: strings.Join(dockerTags, ",")},
+		Output{Name: "major", Value: strconv.FormatUint(components.Semver.Major, 10)},
+		Output{Name: "minor", Value: strconv.FormatUint(components.Semver.Minor, 10)},
+		Output{Name: "patch", Value: strconv.FormatUint(components.Semver.Patch, 10)},
+		Output{Name: "prerelease", Value: prerelease},
+		Output{Name: "is-release", Value: strconv.FormatBool(components.verbatim() && !isPrerelease)},
+		Output{Name: "is-prerelease", Value: strconv.FormatBool(isPrerelease)},
+		Output{Name: "base-tag", Value: components.BaseTag},
+		Output{Name: "distance", Value: strconv.Itoa(components.Distance)},
+		Output{Name: "commit", Value: components.Hash},
+		Output{Name: "short-commit", Value: components.ShortHash},
+		Output{Name: "dirty", Value: strconv.FormatBool(components.Dirty)},
+		Output{Name: "branch", Value: components.Branch},
+	)
+}
+
+// WriteGitHubOutputs writes outputs in the format GitHub Actions expects in
+// the files named by GITHUB_OUTPUT and GITHUB_ENV. Every value uses the
+// heredoc form with a random delimiter so multi-line values are safe.
+func WriteGitHubOutputs(w io.Writer, outputs []Output) error {
+	for _, output := range outputs {
+		delimiter, err := githubDelimiter(output.Value)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", output.Name, delimiter, output.Value, delimiter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnvName converts an output name such as "base-tag" to an environment
+// variable name such as "VERS_BASE_TAG"
+func EnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func githubDelimiter(value string) (string, error) {
+	for {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generating delimiter: %w", err)
+		}
+
+		delimiter := "ghadelimiter_" + hex.EncodeToString(buf)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+// Styles accepted by WriteEnvironment
+const (
+	// EnvStyleEnv writes KEY=value lines, shell-quoting values where needed
+	EnvStyleEnv = "env"
+
+	// EnvStyleDotenv writes KEY=value lines for dotenv files such as GitLab
+	// CI dotenv reports, double-quoting values where needed
+	EnvStyleDotenv = "dotenv"
+
+	// EnvStyleShell writes export statements suitable for eval
+	EnvStyleShell = "shell"
+
+	// EnvStyleMake writes simply-expanded Makefile variable assignments
+	EnvStyleMake = "make"
+)
+
+var shellSafeRe = regexp.MustCompile(`^[A-Za-z0-9_./:+@%,=-]+$`)
+
+// WriteEnvironment writes outputs as variable assignments in the given
+// style, naming each variable with EnvName(prefix, name)
+func WriteEnvironment(w io.Writer, outputs []Output, prefix, style string) error {
+	for _, output := range outputs {
+		name := EnvName(prefix, output.Name)
+
+		var line string
+		switch style {
+		case EnvStyleEnv:
+			line = fmt.Sprintf("%s=%s", name, shellQuote(output.Value, false))
+		case EnvStyleDotenv:
+			line = fmt.Sprintf("%s=%s", name, dotenvQuote(output.Value))
+		case EnvStyleShell:
+			line = fmt.Sprintf("export %s=%s;", name, shellQuote(output.Value, true))
+		case EnvStyleMake:
+			if strings.ContainsAny(output.Value, "\r\n") {
+				return fmt.Errorf("value of %s contains a newline, which make variables cannot hold", name)
+			}
+			line = fmt.Sprintf("%s := %s", name, makeEscape(output.Value))
+		default:
+			return fmt.Errorf("unknown environment style %q", style)
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shellQuote single-quotes a value for POSIX shells, leaving values made of
+// safe characters unquoted unless always is set
+func shellQuote(value string, always bool) string {
+	if !always && shellSafeRe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// dotenvQuote double-quotes a value when it contains characters dotenv
+// parsers treat specially, escaping newlines, quotes and backslashes
+func dotenvQuote(value string) string {
+	if value == "" || shellSafeRe.MatchString(value) {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// makeEscape escapes characters with special meaning on the right-hand side
+// of a make assignment
+func makeEscape(value string) string {
+	replacer := strings.NewReplacer("$", "$$", "#", `\#`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,186 @@
+package vers
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+// parseGitHubOutputs reads heredoc-style outputs back into a map
+func parseGitHubOutputs(t *testing.T, content string) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		name, delimiter, found := strings.Cut(scanner.Text(), "<<")
+		require.True(t, found, "expected heredoc header, got %q", scanner.Text())
+
+		var lines []string
+		for scanner.Scan() && scanner.Text() != delimiter {
+			lines = append(lines, scanner.Text())
+		}
+		values[name] = strings.Join(lines, "\n")
+	}
+
+	return values
+}
+
+func TestOutputs(t *testing.T) {
+	versions := &LanguageVersions{
+		SemVer:     "1.3.0-alpha.1704164645+abcdef12",
+		Python:     "1.3.0a1704164645",
+		JavaScript: "v1.3.0-alpha.1704164645+abcdef12",
+		DotNet:     "1.3.0-alpha.1704164645+abcdef12",
+		Go:         "v1.3.0-alpha.1704164645+abcdef12",
+	}
+
+	t.Run("With components", func(t *testing.T) {
+		components := &VersionComponents{
+			Semver:    semver.MustParse("1.3.0-alpha"),
+			Hash:      "abcdef1234567890abcdef1234567890abcdef12",
+			ShortHash: "abcdef12",
+			Timestamp: time.Unix(1704164645, 0),
+			BaseTag:   "v1.2.0",
+			Distance:  3,
+			Branch:    "main",
+		}
+
+		values := make(map[string]string)
+		for _, output := range Outputs(versions, components) {
+			values[output.Name] = output.Value
+		}
+
+		require.Equal(t, "1.3.0-alpha.1704164645+abcdef12", values["semver"])
+		require.Equal(t, "1.3.0a1704164645", values["python"])
+		require.Equal(t, "1", values["major"])
+		require.Equal(t, "3", values["minor"])
+		require.Equal(t, "0", values["patch"])
+		require.Equal(t, "alpha", values["prerelease"])
+		require.Equal(t, "false", values["is-release"])
+		require.Equal(t, "true", values["is-prerelease"])
+		require.Equal(t, "v1.2.0", values["base-tag"])
+		require.Equal(t, "3", values["distance"])
+		require.Equal(t, "abcdef1234567890abcdef1234567890abcdef12", values["commit"])
+		require.Equal(t, "abcdef12", values["short-commit"])
+		require.Equal(t, "false", values["dirty"])
+		require.Equal(t, "main", values["branch"])
+	})
+
+	t.Run("Exact release", func(t *testing.T) {
+		components := &VersionComponents{Semver: semver.MustParse("1.2.0"), IsExact: true}
+
+		values := make(map[string]string)
+		for _, output := range Outputs(versions, components) {
+			values[output.Name] = output.Value
+		}
+
+		require.Equal(t, "true", values["is-release"])
+		require.Equal(t, "false", values["is-prerelease"])
+		require.Equal(t, "", values["prerelease"])
+	})
+
+	t.Run("Without components", func(t *testing.T) {
+		outputs := Outputs(GenerateFallbackVersion(), nil)
+		require.Equal(t, Output{Name: "semver", Value: "0.0.0-dev"}, outputs[0])
+		for _, output := range outputs {
+			require.NotEqual(t, "major", output.Name)
+		}
+	})
+}
+
+func TestWriteGitHubOutputs(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteGitHubOutputs(&buf, []Output{
+		{Name: "semver", Value: "1.2.3"},
+		{Name: "notes", Value: "line one\nline two"},
+		{Name: "empty", Value: ""},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"semver": "1.2.3",
+		"notes":  "line one\nline two",
+		"empty":  "",
+	}, parseGitHubOutputs(t, buf.String()))
+}
+
+func TestEnvName(t *testing.T) {
+	require.Equal(t, "VERS_SEMVER", EnvName("VERS_", "semver"))
+	require.Equal(t, "VERS_BASE_TAG", EnvName("VERS_", "base-tag"))
+	require.Equal(t, "IS_RELEASE", EnvName("", "is-release"))
+}
+
+func TestWriteEnvironment(t *testing.T) {
+	outputs := []Output{
+		{Name: "semver", Value: "1.2.3+abcdef12"},
+		{Name: "branch", Value: "it's $HOME #1"},
+		{Name: "base-tag", Value: ""},
+	}
+
+	tests := []struct {
+		style    string
+		prefix   string
+		expected string
+	}{
+		{
+			style:  EnvStyleEnv,
+			prefix: "VERS_",
+			expected: "VERS_SEMVER=1.2.3+abcdef12\n" +
+				"VERS_BRANCH='it'\\''s $HOME #1'\n" +
+				"VERS_BASE_TAG=''\n",
+		},
+		{
+			style:  EnvStyleDotenv,
+			prefix: "APP_",
+			expected: "APP_SEMVER=1.2.3+abcdef12\n" +
+				"APP_BRANCH=\"it's $HOME #1\"\n" +
+				"APP_BASE_TAG=\n",
+		},
+		{
+			style:  EnvStyleShell,
+			prefix: "VERS_",
+			expected: "export VERS_SEMVER='1.2.3+abcdef12';\n" +
+				"export VERS_BRANCH='it'\\''s $HOME #1';\n" +
+				"export VERS_BASE_TAG='';\n",
+		},
+		{
+			style:  EnvStyleMake,
+			prefix: "",
+			expected: "SEMVER := 1.2.3+abcdef12\n" +
+				"BRANCH := it's $$HOME \\#1\n" +
+				"BASE_TAG := \n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.style, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteEnvironment(&buf, outputs, test.prefix, test.style)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, buf.String())
+		})
+	}
+
+	t.Run("Multi-line dotenv value", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteEnvironment(&buf, []Output{{Name: "notes", Value: "a \"b\"\nc"}}, "", EnvStyleDotenv)
+		require.NoError(t, err)
+		require.Equal(t, "NOTES=\"a \\\"b\\\"\\nc\"\n", buf.String())
+	})
+
+	t.Run("Multi-line make value", func(t *testing.T) {
+		err := WriteEnvironment(&bytes.Buffer{}, []Output{{Name: "notes", Value: "a\nb"}}, "", EnvStyleMake)
+		require.Error(t, err)
+	})
+
+	t.Run("Unknown style", func(t *testing.T) {
+		err := WriteEnvironment(&bytes.Buffer{}, outputs, "", "yaml")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown environment style")
+	})
+}
@@ -0,0 +1,376 @@
+package vers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	packageEpochRe    = regexp.MustCompile(`^[0-9]+$`)
+	packageRevisionRe = regexp.MustCompile(`^[A-Za-z0-9.+~]+$`)
+
+	// debianUpstreamRe matches the upstream part of a Debian version
+	debianUpstreamRe = regexp.MustCompile(`^[0-9][A-Za-z0-9.+~:-]*$`)
+
+	// rpmPartRe matches the version or release part of an RPM version
+	rpmPartRe = regexp.MustCompile(`^[A-Za-z0-9._+~^]+$`)
+)
+
+// defaultRPMRelease is the RPM Release used when no package revision is set
+const defaultRPMRelease = "1"
+
+func validatePackageOptions(opts Options) error {
+	if opts.PackageEpoch != "" && !packageEpochRe.MatchString(opts.PackageEpoch) {
+		return fmt.Errorf("invalid package epoch %q: must be a non-negative integer", opts.PackageEpoch)
+	}
+	if opts.PackageRevision != "" && !packageRevisionRe.MatchString(opts.PackageRevision) {
+		return fmt.Errorf("invalid package revision %q: may only contain letters, digits, '.', '+' and '~'", opts.PackageRevision)
+	}
+	return nil
+}
+
+// packageUpstreamVersion formats the version for Debian and RPM, where a
+// "~" sorts before anything, including the end of the string, so
+// pre-releases order before their release: 1.3.0~alpha.123+gabcdef12
+func packageUpstreamVersion(components *VersionComponents, opts Options) string {
+	version := fmt.Sprintf("%d.%d.%d", components.Semver.Major, components.Semver.Minor, components.Semver.Patch)
+
+	parts := prereleaseParts(components)
+	if len(parts) > 0 {
+		version += "~" + strings.Join(parts, ".")
+	}
+
+	// The hash uses git describe's "g" prefix so it never reads as a number
+	var build []string
+	if len(parts) > 0 && !opts.OmitCommitHash && !opts.IsPreRelease && opts.Override == "" {
+		build = append(build, "g"+components.ShortHash)
+	}
+	if components.Forced {
+		build = append(build, components.Semver.Build...)
+	}
+	if components.Dirty {
+		build = append(build, "dirty")
+	}
+	if len(build) > 0 {
+		version += "+" + strings.Join(build, ".")
+	}
+
+	return version
+}
+
+// debianVersion formats a full Debian version, [epoch:]upstream[-revision]
+func debianVersion(upstream string, opts Options) string {
+	version := upstream
+	if opts.PackageEpoch != "" {
+		version = opts.PackageEpoch + ":" + version
+	}
+	if opts.PackageRevision != "" {
+		version += "-" + opts.PackageRevision
+	}
+	return version
+}
+
+// rpmRelease returns the RPM Release, the package revision or 1 by default
+func rpmRelease(opts Options) string {
+	if opts.PackageRevision != "" {
+		return opts.PackageRevision
+	}
+	return defaultRPMRelease
+}
+
+// packageFromString converts an existing semantic version string for
+// Debian and RPM by turning its pre-release separator into "~"
+func packageFromString(version string) string {
+	core, build, hasBuild := strings.Cut(version, "+")
+	core = strings.Replace(core, "-", "~", 1)
+	if hasBuild {
+		return core + "+" + build
+	}
+	return core
+}
+
+// ValidateDebian checks a version against the dpkg grammar,
+// [epoch:]upstream[-revision]
+func ValidateDebian(version string) error {
+	upstream, revision, hasRevision, err := cutEVR(version)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid Debian version %q: %w", version, err)
+	case !debianUpstreamRe.MatchString(upstream):
+		return fmt.Errorf("invalid Debian version %q: upstream version must start with a digit and contain only letters, digits, '.', '+', '~', '-' and ':'", version)
+	case hasRevision && !packageRevisionRe.MatchString(revision):
+		return fmt.Errorf("invalid Debian version %q: revision must be letters, digits, '.', '+' and '~'", version)
+	}
+	return nil
+}
+
+// ValidateRPM checks a version against the RPM grammar,
+// [epoch:]version[-release]
+func ValidateRPM(version string) error {
+	upstream, release, hasRelease, err := cutEVR(version)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid RPM version %q: %w", version, err)
+	case !rpmPartRe.MatchString(upstream):
+		return fmt.Errorf("invalid RPM version %q: version must be letters, digits, '.', '_', '+', '~' and '^'", version)
+	case hasRelease && !rpmPartRe.MatchString(release):
+		return fmt.Errorf("invalid RPM version %q: release must be letters, digits, '.', '_', '+', '~' and '^'", version)
+	}
+	return nil
+}
+
+// cutEVR splits [epoch:]version[-release] like splitEVR, checking the epoch
+func cutEVR(version string) (string, string, bool, error) {
+	if epoch, after, found := strings.Cut(version, ":"); found {
+		if !packageEpochRe.MatchString(epoch) {
+			return "", "", false, fmt.Errorf("epoch must be a non-negative integer")
+		}
+		version = after
+	}
+
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return version[:i], version[i+1:], true, nil
+	}
+	return version, "", false, nil
+}
+
+// CompareDebian compares two Debian versions using the algorithm of
+// dpkg --compare-versions, returning -1, 0 or 1
+func CompareDebian(a, b string) int {
+	aEpoch, aUpstream, aRevision := splitEVR(a)
+	bEpoch, bUpstream, bRevision := splitEVR(b)
+
+	if aEpoch != bEpoch {
+		return sign(aEpoch - bEpoch)
+	}
+	if c := debianVerrevcmp(aUpstream, bUpstream); c != 0 {
+		return c
+	}
+	return debianVerrevcmp(aRevision, bRevision)
+}
+
+// splitEVR splits [epoch:]version[-release], the layout shared by Debian
+// and RPM; the release follows the last hyphen
+func splitEVR(version string) (int, string, string) {
+	epoch := 0
+	if before, after, found := strings.Cut(version, ":"); found {
+		epoch, _ = strconv.Atoi(before)
+		version = after
+	}
+
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+	return epoch, version, ""
+}
+
+// debianOrder ranks a non-digit character: "~" before the end of the
+// string, then letters, then everything else
+func debianOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case c >= '0' && c <= '9':
+		return 0
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// debianVerrevcmp is dpkg's verrevcmp, comparing alternating runs of
+// non-digits and digits
+func debianVerrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			if ac, bc := debianOrder(a, i), debianOrder(b, j); ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+
+	return 0
+}
+
+// CompareRPM compares two RPM [epoch:]version[-release] strings using
+// rpmvercmp, including "~" pre-release and "^" post-release handling,
+// returning -1, 0 or 1
+func CompareRPM(a, b string) int {
+	aEpoch, aVersion, aRelease := splitEVR(a)
+	bEpoch, bVersion, bRelease := splitEVR(b)
+
+	if aEpoch != bEpoch {
+		return sign(aEpoch - bEpoch)
+	}
+	if c := rpmvercmp(aVersion, bVersion); c != 0 {
+		return c
+	}
+	return rpmvercmp(aRelease, bRelease)
+}
+
+// rpmvercmp compares alternating alphabetic and numeric segments,
+// ignoring other separators. Numeric segments are newer than alphabetic ones.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// A tilde sorts before everything, even the end of the string
+		if at(a, i) == '~' || at(b, j) == '~' {
+			if at(a, i) != '~' {
+				return 1
+			}
+			if at(b, j) != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// A caret sorts after the end of the string but before anything else
+		if at(a, i) == '^' || at(b, j) == '^' {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if a[i] != '^' {
+				return 1
+			}
+			if b[j] != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		startA, startB := i, j
+		numeric := isDigit(a[i])
+		if numeric {
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isAlpha(a[i]) {
+				i++
+			}
+			for j < len(b) && isAlpha(b[j]) {
+				j++
+			}
+		}
+
+		// Segments of different types: numeric is newer
+		if j == startB {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		segA, segB := a[startA:i], b[startB:j]
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return sign(len(segA) - len(segB))
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	default:
+		return -1
+	}
+}
+
+func at(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,223 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageUpstreamVersion(t *testing.T) {
+	timestamp := time.Unix(1704164645, 0)
+
+	tests := []struct {
+		name       string
+		components VersionComponents
+		opts       Options
+		expected   string
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0"), IsExact: true, ShortHash: "abcdef12"},
+			expected:   "1.3.0",
+		},
+		{
+			name:       "Release candidate tag",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-rc.1"), IsExact: true, ShortHash: "abcdef12"},
+			expected:   "1.3.0~rc.1+gabcdef12",
+		},
+		{
+			name:       "Untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			expected:   "1.3.0~alpha.1704164645+gabcdef12",
+		},
+		{
+			name:       "Omit hash",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			opts:       Options{OmitCommitHash: true},
+			expected:   "1.3.0~alpha.1704164645",
+		},
+		{
+			name:       "Dirty release",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0"), IsExact: true, Dirty: true, ShortHash: "abcdef12"},
+			expected:   "1.3.0+dirty",
+		},
+		{
+			name:       "Dirty untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, Dirty: true, ShortHash: "abcdef12"},
+			expected:   "1.3.0~alpha.1704164645+gabcdef12.dirty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, packageUpstreamVersion(&test.components, test.opts))
+		})
+	}
+}
+
+func TestCalculatePackageVersions(t *testing.T) {
+	t.Run("Epoch and revision", func(t *testing.T) {
+		version, err := Calculate(Options{
+			Archival:        testArchival("v1.2.3-4-g0123456", ""),
+			PackageEpoch:    "2",
+			PackageRevision: "3",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "2:1.3.0~alpha.1704164645+g01234567-3", version.Debian)
+		require.Equal(t, "1.3.0~alpha.1704164645+g01234567", version.RPM)
+		require.Equal(t, "3", version.RPMRelease)
+	})
+
+	t.Run("Default RPM release", func(t *testing.T) {
+		version, err := Calculate(Options{Archival: testArchival("v1.2.3", "")})
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3", version.Debian)
+		require.Equal(t, "1.2.3", version.RPM)
+		require.Equal(t, "1", version.RPMRelease)
+	})
+
+	t.Run("Invalid epoch", func(t *testing.T) {
+		_, err := Calculate(Options{Archival: testArchival("v1.2.3", ""), PackageEpoch: "one"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid package epoch")
+	})
+
+	t.Run("Invalid revision", func(t *testing.T) {
+		_, err := Calculate(Options{Archival: testArchival("v1.2.3", ""), PackageRevision: "1-2"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid package revision")
+	})
+}
+
+// TestPackageVersionOrdering checks that generated versions sort in release
+// order, where the equivalent semantic versions with a "-" would not
+func TestPackageVersionOrdering(t *testing.T) {
+	build := func(version string, exact bool, timestamp int64) string {
+		return packageUpstreamVersion(&VersionComponents{
+			Semver:    semver.MustParse(version),
+			IsExact:   exact,
+			Timestamp: time.Unix(timestamp, 0),
+			ShortHash: "abcdef12",
+		}, Options{})
+	}
+
+	ordered := []string{
+		build("1.2.0-rc.1", true, 0),
+		build("1.2.0", true, 0),
+		build("1.3.0-alpha", false, 1704164645),
+		build("1.3.0-alpha", false, 1704164700),
+		build("1.3.0-beta.1", true, 0),
+		build("1.3.0-rc.1", true, 0),
+		build("1.3.0-rc.2", true, 0),
+		build("1.3.0", true, 0),
+		build("1.3.1-alpha", false, 1704164800),
+		build("1.10.0", true, 0),
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := ordered[i], ordered[i+1]
+		require.Equal(t, -1, CompareDebian(a, b), "dpkg: %s < %s", a, b)
+		require.Equal(t, 1, CompareDebian(b, a), "dpkg: %s > %s", b, a)
+		require.Equal(t, -1, CompareRPM(a, b), "rpm: %s < %s", a, b)
+		require.Equal(t, 1, CompareRPM(b, a), "rpm: %s > %s", b, a)
+	}
+}
+
+func TestCompareDebian(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.00", 0},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0~", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0a", "1.0+", -1},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0", "1.0-0", 0},
+		{"1:0.1", "2.0", 1},
+		{"1.2", "1.10", -1},
+		{"1.3.0-alpha.123", "1.3.0", 1},
+		{"1.3.0~alpha.123+gabcdef12", "1.3.0", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" vs "+test.b, func(t *testing.T) {
+			require.Equal(t, test.expected, CompareDebian(test.a, test.b))
+			require.Equal(t, -test.expected, CompareDebian(test.b, test.a))
+		})
+	}
+}
+
+func TestCompareRPM(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.1", -1},
+		{"1.0", "1.00", 0},
+		{"2.0", "10.0", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0.1", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1~git1", "1.0~rc1", -1},
+		{"1.0^", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0_1", "1.0.1", 0},
+		{"a", "1", -1},
+		{"1.0-1", "1.0-2", -1},
+		{"1:1.0", "2.0", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" vs "+test.b, func(t *testing.T) {
+			require.Equal(t, test.expected, CompareRPM(test.a, test.b))
+			require.Equal(t, -test.expected, CompareRPM(test.b, test.a))
+		})
+	}
+}
+
+func TestValidatePackageVersions(t *testing.T) {
+	tests := []struct {
+		version string
+		debian  string
+		rpm     string
+	}{
+		{"1.2.3", "", ""},
+		{"1:1.2.3~rc1+gabcdef12-1ubuntu1", "", ""},
+		{"1.2.3^git1-1.fc40", "upstream version must start with a digit", ""},
+		{"1.0_1", "upstream version must start with a digit", ""},
+		{"2.0-rc-1", "", "version must be letters"},
+		{"v1.2.3", "upstream version must start with a digit", ""},
+		{"1.2.3-", "revision must be letters", "release must be letters"},
+		{"x:1.2.3", "epoch must be a non-negative integer", "epoch must be a non-negative integer"},
+		{"latest version", "upstream version must start with a digit", "version must be letters"},
+		{"", "upstream version must start with a digit", "version must be letters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			for _, check := range []struct {
+				err      error
+				expected string
+			}{
+				{ValidateDebian(test.version), test.debian},
+				{ValidateRPM(test.version), test.rpm},
+			} {
+				if check.expected == "" {
+					require.NoError(t, check.err)
+					continue
+				}
+				require.Error(t, check.err)
+				require.Contains(t, check.err.Error(), check.expected)
+			}
+		})
+	}
+}
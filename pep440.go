@@ -0,0 +1,363 @@
+package vers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// pep440Re is the version pattern from PEP 440 Appendix B, accepting every
+// permitted spelling so versions can be normalized
+var pep440Re = regexp.MustCompile(`(?i)^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?P<dev>[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?` +
+	`\s*$`)
+
+// pep440PreLabels maps the alternative pre-release spellings PEP 440
+// allows onto their normalized form
+var pep440PreLabels = map[string]string{
+	"a":       "a",
+	"alpha":   "a",
+	"b":       "b",
+	"beta":    "b",
+	"c":       "rc",
+	"rc":      "rc",
+	"pre":     "rc",
+	"preview": "rc",
+}
+
+// pep440PreOrder orders normalized pre-release labels
+var pep440PreOrder = map[string]int{"a": 0, "b": 1, "rc": 2}
+
+// PEP440Version is a parsed Python package version,
+// [N!]N(.N)*[{a|b|rc}N][.postN][.devN][+local]
+type PEP440Version struct {
+	Epoch   uint64
+	Release []uint64
+
+	// PreLabel is "a", "b" or "rc", empty for versions that are not
+	// pre-releases
+	PreLabel  string
+	PreNumber uint64
+
+	HasPost bool
+	Post    uint64
+
+	HasDev bool
+	Dev    uint64
+
+	// Local holds the lower-cased local version segments after "+"
+	Local []string
+}
+
+// HasMarker reports whether v is a pre-, post- or dev-release
+func (v PEP440Version) HasMarker() bool {
+	return v.PreLabel != "" || v.HasPost || v.HasDev
+}
+
+// ParsePEP440 parses any PEP 440 spelling of a version, e.g.
+// "v1.0-Alpha-1.POST2" or "1!2.0rc1.dev3+ubuntu-1"
+func ParsePEP440(version string) (PEP440Version, error) {
+	match := pep440Re.FindStringSubmatch(version)
+	if match == nil {
+		return PEP440Version{}, fmt.Errorf("invalid PEP 440 version %q", version)
+	}
+
+	group := func(name string) string {
+		return match[pep440Re.SubexpIndex(name)]
+	}
+	number := func(name string) (uint64, error) {
+		if group(name) == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(group(name), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid PEP 440 version %q: %w", version, err)
+		}
+		return n, nil
+	}
+
+	var v PEP440Version
+	var err error
+
+	if v.Epoch, err = number("epoch"); err != nil {
+		return PEP440Version{}, err
+	}
+
+	for _, part := range strings.Split(group("release"), ".") {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return PEP440Version{}, fmt.Errorf("invalid PEP 440 version %q: %w", version, err)
+		}
+		v.Release = append(v.Release, n)
+	}
+
+	if group("pre") != "" {
+		v.PreLabel = pep440PreLabels[strings.ToLower(group("pre_l"))]
+		if v.PreNumber, err = number("pre_n"); err != nil {
+			return PEP440Version{}, err
+		}
+	}
+
+	if group("post") != "" {
+		v.HasPost = true
+		name := "post_n2"
+		if group("post_n1") != "" {
+			name = "post_n1"
+		}
+		if v.Post, err = number(name); err != nil {
+			return PEP440Version{}, err
+		}
+	}
+
+	if group("dev") != "" {
+		v.HasDev = true
+		if v.Dev, err = number("dev_n"); err != nil {
+			return PEP440Version{}, err
+		}
+	}
+
+	if local := group("local"); local != "" {
+		v.Local = pep440LocalSegments(local)
+	}
+
+	return v, nil
+}
+
+// String returns the normalized form of the version
+func (v PEP440Version) String() string {
+	var b strings.Builder
+
+	if v.Epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.Epoch)
+	}
+
+	for i, part := range v.Release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.FormatUint(part, 10))
+	}
+
+	if v.PreLabel != "" {
+		fmt.Fprintf(&b, "%s%d", v.PreLabel, v.PreNumber)
+	}
+	if v.HasPost {
+		fmt.Fprintf(&b, ".post%d", v.Post)
+	}
+	if v.HasDev {
+		fmt.Fprintf(&b, ".dev%d", v.Dev)
+	}
+	if len(v.Local) > 0 {
+		b.WriteString("+" + strings.Join(v.Local, "."))
+	}
+
+	return b.String()
+}
+
+// Compare orders versions as packaging.version does, returning -1, 0 or 1.
+// Within a release, dev releases sort first, then pre-releases, the
+// release, and post-releases; local versions sort after their public version.
+func (v PEP440Version) Compare(o PEP440Version) int {
+	if c := compareUint(v.Epoch, o.Epoch); c != 0 {
+		return c
+	}
+
+	// Trailing zeros are insignificant, so 1.0 == 1.0.0
+	for i := 0; i < max(len(v.Release), len(o.Release)); i++ {
+		if c := compareUint(releasePart(v.Release, i), releasePart(o.Release, i)); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInt(v.preRank(), o.preRank()); c != 0 {
+		return c
+	}
+	if v.PreLabel != "" && o.PreLabel != "" {
+		if c := compareUint(v.PreNumber, o.PreNumber); c != 0 {
+			return c
+		}
+	}
+
+	// A post-release sorts after no post-release
+	if c := compareOptional(v.HasPost, v.Post, o.HasPost, o.Post, -1); c != 0 {
+		return c
+	}
+
+	// A dev release sorts before no dev release
+	if c := compareOptional(v.HasDev, v.Dev, o.HasDev, o.Dev, 1); c != 0 {
+		return c
+	}
+
+	return compareLocal(v.Local, o.Local)
+}
+
+// preRank orders the pre-release phase: dev-only releases of a version
+// (1.0.dev1) before its pre-releases, then a, b and rc, then everything else
+func (v PEP440Version) preRank() int {
+	switch {
+	case v.PreLabel != "":
+		return pep440PreOrder[v.PreLabel]
+	case v.HasDev && !v.HasPost:
+		return -1
+	default:
+		return len(pep440PreOrder)
+	}
+}
+
+func releasePart(release []uint64, i int) uint64 {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// compareOptional compares two optional numbers, ranking a missing number
+// as missing (-1 for before any number, 1 for after)
+func compareOptional(aHas bool, a uint64, bHas bool, b uint64, missing int) int {
+	switch {
+	case aHas && bHas:
+		return compareUint(a, b)
+	case aHas == bHas:
+		return 0
+	case aHas:
+		return -missing
+	default:
+		return missing
+	}
+}
+
+// compareLocal compares local segments: numeric segments sort after
+// alphanumeric ones, and a longer local version wins when one is a prefix
+func compareLocal(a, b []string) int {
+	for i := 0; i < min(len(a), len(b)); i++ {
+		aNum, aErr := strconv.ParseUint(a[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(b[i], 10, 64)
+
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareUint(aNum, bNum)
+		case aErr == nil:
+			c = 1
+		case bErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareInt(len(a), len(b))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt(a, b int) int {
+	return sign(a - b)
+}
+
+// pythonVersion formats the calculated version for PEP 440. Pre-release
+// labels map to a, b and rc, dev builds become .devN, and the commit hash
+// and dirty flag are local segments, e.g. 1.3.0a1704164645+gabcdef12.dirty.
+// Package indexes reject local versions, so OmitCommitHash gives uploadable
+// versions for clean trees.
+func pythonVersion(components *VersionComponents, opts Options) PEP440Version {
+	v := PEP440Version{
+		Release: []uint64{components.Semver.Major, components.Semver.Minor, components.Semver.Patch},
+	}
+
+	parts := prereleaseParts(components)
+	if len(parts) > 0 {
+		var number uint64
+		if len(parts) > 1 {
+			number, _ = strconv.ParseUint(parts[1], 10, 64)
+		}
+
+		if parts[0] == "dev" {
+			v.HasDev, v.Dev = true, number
+		} else {
+			v.PreLabel, v.PreNumber = pep440PreLabels[parts[0]], number
+		}
+
+		if !opts.OmitCommitHash && !opts.IsPreRelease && opts.Override == "" && components.ShortHash != "" {
+			v.Local = append(v.Local, "g"+components.ShortHash)
+		}
+	}
+
+	if components.Forced {
+		for _, build := range components.Semver.Build {
+			v.Local = append(v.Local, pep440LocalSegments(build)...)
+		}
+	}
+	if components.Dirty {
+		v.Local = append(v.Local, "dirty")
+	}
+
+	return v
+}
+
+// pythonFromString converts an existing version string for PEP 440. Any
+// PEP 440 spelling is normalized. Semantic versions PEP 440 cannot read, or
+// would misread such as the pre-release 1.2.3-1, keep their number as a dev
+// release and their labels as local segments: 1.2.3-nightly.4 becomes
+// 1.2.3.dev4+nightly.
+func pythonFromString(version string) (string, error) {
+	parsed, semverErr := semver.Parse(strings.TrimPrefix(version, "v"))
+	numericPre := semverErr == nil && len(parsed.Pre) > 0 && parsed.Pre[0].IsNum
+
+	if !numericPre {
+		if v, err := ParsePEP440(version); err == nil {
+			return v.String(), nil
+		}
+	}
+
+	if semverErr != nil {
+		return "", fmt.Errorf("%q is neither a PEP 440 nor a semantic version", version)
+	}
+
+	v := PEP440Version{Release: []uint64{parsed.Major, parsed.Minor, parsed.Patch}}
+	if len(parsed.Pre) > 0 {
+		v.HasDev = true
+	}
+
+	numbered := false
+	for _, pre := range parsed.Pre {
+		if pre.IsNum && !numbered {
+			v.Dev, numbered = pre.VersionNum, true
+			continue
+		}
+		v.Local = append(v.Local, pep440LocalSegments(pre.String())...)
+	}
+	for _, build := range parsed.Build {
+		v.Local = append(v.Local, pep440LocalSegments(build)...)
+	}
+
+	return v.String(), nil
+}
+
+// pep440LocalSegments splits a string into lower-cased alphanumeric local
+// version segments
+func pep440LocalSegments(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
@@ -0,0 +1,194 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+// pep440Ordered is the sorted version corpus from the packaging project's
+// test suite, which packaging.version orders exactly as listed
+var pep440Ordered = []string{
+	// Implicit epoch of 0
+	"1.0.dev456", "1.0a1", "1.0a2.dev456", "1.0a12.dev456", "1.0a12",
+	"1.0b1.dev456", "1.0b2", "1.0b2.post345.dev456", "1.0b2.post345",
+	"1.0b2-346", "1.0c1.dev456", "1.0c1", "1.0rc2", "1.0c3", "1.0",
+	"1.0.post456.dev34", "1.0.post456", "1.1.dev1", "1.2+123abc",
+	"1.2+123abc456", "1.2+abc", "1.2+abc123", "1.2+abc123def", "1.2+1234.abc",
+	"1.2+123456", "1.2.r32+123456", "1.2.rev33+123456",
+
+	// Explicit epoch of 1
+	"1!1.0.dev456", "1!1.0a1", "1!1.0a2.dev456", "1!1.0a12.dev456", "1!1.0a12",
+	"1!1.0b1.dev456", "1!1.0b2", "1!1.0b2.post345.dev456", "1!1.0b2.post345",
+	"1!1.0b2-346", "1!1.0c1.dev456", "1!1.0c1", "1!1.0rc2", "1!1.0c3", "1!1.0",
+	"1!1.0.post456.dev34", "1!1.0.post456", "1!1.1.dev1", "1!1.2+123abc",
+	"1!1.2+123abc456", "1!1.2+abc", "1!1.2+abc123", "1!1.2+abc123def",
+	"1!1.2+1234.abc", "1!1.2+123456", "1!1.2.r32+123456", "1!1.2.rev33+123456",
+}
+
+func TestPEP440Ordering(t *testing.T) {
+	versions := make([]PEP440Version, len(pep440Ordered))
+	for i, s := range pep440Ordered {
+		v, err := ParsePEP440(s)
+		require.NoError(t, err, s)
+		versions[i] = v
+	}
+
+	for i := range versions {
+		for j := range versions {
+			expected := compareInt(i, j)
+			require.Equal(t, expected, versions[i].Compare(versions[j]),
+				"%s vs %s", pep440Ordered[i], pep440Ordered[j])
+		}
+	}
+}
+
+func TestPEP440Equality(t *testing.T) {
+	tests := [][2]string{
+		{"1.0", "1.0.0"},
+		{"1.0", "1.0.0.0"},
+		{"1.0a1", "1.0-alpha.1"},
+		{"1.0.post0", "1.0-r"},
+		{"1.0+ubuntu.1", "1.0+ubuntu-1"},
+		{"0!1.0", "1.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test[0]+" == "+test[1], func(t *testing.T) {
+			a, err := ParsePEP440(test[0])
+			require.NoError(t, err)
+			b, err := ParsePEP440(test[1])
+			require.NoError(t, err)
+			require.Equal(t, 0, a.Compare(b))
+		})
+	}
+}
+
+func TestParsePEP440(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Normalization cases from PEP 440
+		{"v1.0", "1.0"},
+		{"01.02.003", "1.2.3"},
+		{"1.0ALPHA1", "1.0a1"},
+		{"1.0-alpha.1", "1.0a1"},
+		{"1.0.a.1", "1.0a1"},
+		{"1.0beta", "1.0b0"},
+		{"1.0c2", "1.0rc2"},
+		{"1.0pre2", "1.0rc2"},
+		{"1.0preview2", "1.0rc2"},
+		{"1.0-1", "1.0.post1"},
+		{"1.0-post", "1.0.post0"},
+		{"1.0.rev4", "1.0.post4"},
+		{"1.0r4", "1.0.post4"},
+		{"1.0-DEV", "1.0.dev0"},
+		{"1.0_dev_2", "1.0.dev2"},
+		{"1.0+Ubuntu_1-2", "1.0+ubuntu.1.2"},
+		{"0!1.0", "1.0"},
+		{"2!1.0rc1.post2.dev3+local", "2!1.0rc1.post2.dev3+local"},
+		{"  1.0\n", "1.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			v, err := ParsePEP440(test.input)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, v.String())
+		})
+	}
+
+	for _, invalid := range []string{"", "french toast", "1.0+", "1.0+ubuntu..1", "1.0-alpha-beta", "1.0dev-post"} {
+		t.Run("Invalid "+invalid, func(t *testing.T) {
+			_, err := ParsePEP440(invalid)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid PEP 440 version")
+		})
+	}
+}
+
+func TestPythonVersion(t *testing.T) {
+	timestamp := time.Unix(1704164645, 0)
+
+	tests := []struct {
+		name       string
+		components VersionComponents
+		opts       Options
+		expected   string
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, ShortHash: "abcdef12"},
+			expected:   "1.2.3",
+		},
+		{
+			name:       "Dirty release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, Dirty: true, ShortHash: "abcdef12"},
+			expected:   "1.2.3+dirty",
+		},
+		{
+			name:       "Release candidate tag",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3-rc.2"), IsExact: true, ShortHash: "abcdef12"},
+			opts:       Options{OmitCommitHash: true},
+			expected:   "1.2.3rc2",
+		},
+		{
+			name:       "Untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			expected:   "1.3.0a1704164645+gabcdef12",
+		},
+		{
+			name:       "Untagged dirty build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-beta"), Timestamp: timestamp, Dirty: true, ShortHash: "abcdef12"},
+			expected:   "1.3.0b1704164645+gabcdef12.dirty",
+		},
+		{
+			name:       "Dev build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-dev"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			opts:       Options{OmitCommitHash: true},
+			expected:   "1.3.0.dev1704164645",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := pythonVersion(&test.components, test.opts)
+			require.Equal(t, test.expected, v.String())
+
+			// Generated versions are already normalized
+			parsed, err := ParsePEP440(v.String())
+			require.NoError(t, err)
+			require.Equal(t, v.String(), parsed.String())
+		})
+	}
+}
+
+func TestPythonFromString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"1.2.3-alpha.1", "1.2.3a1"},
+		{"1.2.3-rc.1+abcdef12", "1.2.3rc1+abcdef12"},
+		{"1.2.3-dev", "1.2.3.dev0"},
+		{"1.2.3.post1", "1.2.3.post1"},
+		{"1.2.3-1", "1.2.3.dev1"},
+		{"1.2.3-nightly.4", "1.2.3.dev4+nightly"},
+		{"1.2.3-nightly.4+build.5", "1.2.3.dev4+nightly.build.5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			python, err := pythonFromString(test.input)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, python)
+		})
+	}
+
+	_, err := pythonFromString("1.2.x")
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package vers
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+)
+
+// Release policy rules reported by CheckPolicy
+const (
+	PolicyDowngrade    = "downgrade"
+	PolicyDuplicate    = "duplicate"
+	PolicySkippedMajor = "skipped-major"
+)
+
+// Policy configures the release guardrails enforced by CheckPolicy. Every
+// rule is enforced unless explicitly allowed.
+type Policy struct {
+	// AllowDowngrade permits versions lower than an existing tag
+	AllowDowngrade bool
+
+	// AllowDuplicate permits versions already tagged on another commit
+	AllowDuplicate bool
+
+	// AllowSkippedMajor permits versions more than one major version above
+	// the highest tag, e.g. 3.0.0 after v1.4.0
+	AllowSkippedMajor bool
+
+	// Constraint limits the releases a version must not be lower than to a
+	// range such as "~1.4", so maintenance branches are not compared with
+	// newer release lines. Duplicates are still checked against every tag.
+	Constraint string
+}
+
+// Violation is a release policy rule broken by a version
+type Violation struct {
+	Rule    string `json:"rule"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// CheckPolicy checks a calculated version against the repository tags, as
+// returned by ListTags, and returns the policy violations. A tag on the
+// version's own commit is not a duplicate, so exact tag builds pass. Only
+// final releases count as downgrades, so alpha builds after a release
+// candidate pass. Build metadata is ignored when comparing versions.
+func CheckPolicy(version Version, tags []Tag, policy Policy) ([]Violation, error) {
+	inRange := func(semver.Version) bool { return true }
+	if policy.Constraint != "" {
+		var err error
+		inRange, err = parseConstraint(policy.Constraint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var violations []Violation
+	var highest, latest *Tag
+
+	for i, tag := range tags {
+		sameCommit := version.Hash != "" && tag.Commit == version.Hash
+		if version.Compare(Version{Version: tag.Version}) == 0 && !sameCommit && !policy.AllowDuplicate {
+			violations = append(violations, Violation{
+				Rule:    PolicyDuplicate,
+				Tag:     tag.Name,
+				Message: fmt.Sprintf("%s was already released as %s on commit %.8s", version, tag.Name, tag.Commit),
+			})
+		}
+
+		if highest == nil || tag.Version.GT(highest.Version) {
+			highest = &tags[i]
+		}
+		if len(tag.Version.Pre) == 0 && inRange(tag.Version) && (latest == nil || tag.Version.GT(latest.Version)) {
+			latest = &tags[i]
+		}
+	}
+
+	if latest != nil && version.Version.LT(latest.Version) && !policy.AllowDowngrade {
+		violations = append(violations, Violation{
+			Rule:    PolicyDowngrade,
+			Tag:     latest.Name,
+			Message: fmt.Sprintf("%s is lower than the latest release %s", version, latest.Name),
+		})
+	}
+
+	if highest != nil && version.Major > highest.Version.Major+1 && !policy.AllowSkippedMajor {
+		violations = append(violations, Violation{
+			Rule:    PolicySkippedMajor,
+			Tag:     highest.Name,
+			Message: fmt.Sprintf("%s skips major version %d after %s", version, highest.Version.Major+1, highest.Name),
+		})
+	}
+
+	return violations, nil
+}
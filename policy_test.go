@@ -0,0 +1,107 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicyTags(tags map[string]string) []Tag {
+	var result []Tag
+	for name, commit := range tags {
+		result = append(result, Tag{
+			Name:    name,
+			Version: semver.MustParse(name[1:]),
+			Commit:  commit,
+		})
+	}
+	return result
+}
+
+func TestCheckPolicy(t *testing.T) {
+	tags := testPolicyTags(map[string]string{
+		"v1.0.0": "1111111111111111111111111111111111111111",
+		"v1.4.0": "4444444444444444444444444444444444444444",
+		"v2.0.0": "2222222222222222222222222222222222222222",
+	})
+
+	tests := []struct {
+		name     string
+		version  string
+		hash     string
+		policy   Policy
+		expected []string
+	}{
+		{"Next minor", "2.1.0-alpha.1704164645+abcdef12", "abcdef12", Policy{}, nil},
+		{"Next major", "3.0.0", "abcdef12", Policy{}, nil},
+		{"Exact tag build", "2.0.0", "2222222222222222222222222222222222222222", Policy{}, nil},
+		{"Downgrade", "1.5.0", "abcdef12", Policy{}, []string{PolicyDowngrade}},
+		{"Duplicate", "1.4.0+abcdef12", "abcdef12", Policy{}, []string{PolicyDuplicate, PolicyDowngrade}},
+		{"Duplicate of latest", "2.0.0", "abcdef12", Policy{}, []string{PolicyDuplicate}},
+		{"Skipped major", "4.0.0", "abcdef12", Policy{}, []string{PolicySkippedMajor}},
+		{"Allowed downgrade", "1.5.0", "abcdef12", Policy{AllowDowngrade: true}, nil},
+		{"Allowed duplicate", "2.0.0", "abcdef12", Policy{AllowDuplicate: true}, nil},
+		{"Allowed skipped major", "4.0.0", "abcdef12", Policy{AllowSkippedMajor: true}, nil},
+		{"Maintenance release", "1.4.1", "abcdef12", Policy{Constraint: "~1.4"}, nil},
+		{"Downgrade within constraint", "1.3.0", "abcdef12", Policy{Constraint: "^1.0"}, []string{PolicyDowngrade}},
+		{"Duplicate outside constraint", "2.0.0", "abcdef12", Policy{Constraint: "~1.4"}, []string{PolicyDuplicate}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			version, err := Parse(test.version)
+			require.NoError(t, err)
+			version.Hash = test.hash
+
+			violations, err := CheckPolicy(version, tags, test.policy)
+			require.NoError(t, err)
+
+			var rules []string
+			for _, violation := range violations {
+				rules = append(rules, violation.Rule)
+			}
+			require.Equal(t, test.expected, rules)
+		})
+	}
+
+	t.Run("Descriptive messages", func(t *testing.T) {
+		version, _ := Parse("1.4.0")
+		violations, err := CheckPolicy(version, tags, Policy{})
+		require.NoError(t, err)
+		require.Len(t, violations, 2)
+		require.Equal(t, "v1.4.0", violations[0].Tag)
+		require.Equal(t, "1.4.0 was already released as v1.4.0 on commit 44444444", violations[0].Message)
+		require.Equal(t, "v2.0.0", violations[1].Tag)
+		require.Equal(t, "1.4.0 is lower than the latest release v2.0.0", violations[1].Message)
+	})
+
+	t.Run("Pre-release tags", func(t *testing.T) {
+		tags := append(testPolicyTags(map[string]string{"v1.2.0": "1111111111111111111111111111111111111111"}),
+			Tag{Name: "v1.3.0-rc.1", Version: semver.MustParse("1.3.0-rc.1"), Commit: "3333333333333333333333333333333333333333"})
+
+		version, _ := Parse("1.3.0-alpha.1704164645+abcdef12")
+		violations, err := CheckPolicy(version, tags, Policy{})
+		require.NoError(t, err)
+		require.Empty(t, violations)
+
+		version, _ = Parse("1.1.0")
+		violations, err = CheckPolicy(version, tags, Policy{})
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		require.Equal(t, "v1.2.0", violations[0].Tag)
+	})
+
+	t.Run("No tags", func(t *testing.T) {
+		version, _ := Parse("5.0.0")
+		violations, err := CheckPolicy(version, nil, Policy{})
+		require.NoError(t, err)
+		require.Empty(t, violations)
+	})
+
+	t.Run("Invalid constraint", func(t *testing.T) {
+		version, _ := Parse("1.0.0")
+		_, err := CheckPolicy(version, tags, Policy{Constraint: "~1.x"})
+		require.Error(t, err)
+	})
+}
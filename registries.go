@@ -0,0 +1,74 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// registryVersions holds the package registry formats of a version
+type registryVersions struct {
+	npm      string
+	rubyGems string
+	cargo    string
+}
+
+// buildRegistryVersions formats a semantic version string for npm, Cargo
+// and RubyGems. npm and Cargo take semantic versions without the "v" that
+// npm strips and Cargo rejects. RubyGems joins pre-release parts with dots
+// (1.3.0.alpha.123) and has no build metadata, so the hash is dropped and
+// dirty builds get a "dirty" segment, which makes them pre-releases.
+func buildRegistryVersions(version string, components *VersionComponents) registryVersions {
+	gem := fmt.Sprintf("%d.%d.%d", components.Semver.Major, components.Semver.Minor, components.Semver.Patch)
+	for _, part := range prereleaseParts(components) {
+		gem += "." + rubyGemsSegment(part)
+	}
+	if components.Dirty {
+		gem += ".dirty"
+	}
+
+	return registryVersions{
+		npm:      version,
+		rubyGems: gem,
+		cargo:    version,
+	}
+}
+
+// registryFromString converts an existing version string for npm, Cargo
+// and RubyGems, treating it as exact. A "dirty" build identifier is kept
+// as a RubyGems segment.
+func registryFromString(version string) registryVersions {
+	parsed, err := semver.Parse(version)
+	if err != nil {
+		// Best effort for versions blang/semver rejects, e.g. leading zeros
+		core, _, _ := strings.Cut(version, "+")
+		return registryVersions{
+			npm:      version,
+			rubyGems: strings.ReplaceAll(core, "-", "."),
+			cargo:    version,
+		}
+	}
+
+	gem := fmt.Sprintf("%d.%d.%d", parsed.Major, parsed.Minor, parsed.Patch)
+	for _, pre := range parsed.Pre {
+		gem += "." + rubyGemsSegment(pre.String())
+	}
+	for _, build := range parsed.Build {
+		if build == "dirty" {
+			gem += ".dirty"
+		}
+	}
+
+	return registryVersions{
+		npm:      version,
+		rubyGems: gem,
+		cargo:    version,
+	}
+}
+
+// rubyGemsSegment converts a pre-release identifier into RubyGems
+// segments, which are dot-separated and alphanumeric
+func rubyGemsSegment(part string) string {
+	return strings.Join(strings.FieldsFunc(part, func(r rune) bool { return r == '-' }), ".")
+}
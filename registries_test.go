@@ -0,0 +1,125 @@
+package vers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// semverGrammarRe is the semver.org grammar, which npm (node-semver in
+	// strict mode) and Cargo both implement
+	semverGrammarRe = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+	// rubyGemsGrammarRe is Gem::Version::ANCHORED_VERSION_PATTERN
+	rubyGemsGrammarRe = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9a-zA-Z]+)*(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?)?\s*$`)
+)
+
+func TestRegistryVersions(t *testing.T) {
+	timestamp := time.Unix(1704164645, 0)
+
+	tests := []struct {
+		name       string
+		components VersionComponents
+		opts       Options
+		npm        string
+		rubyGems   string
+		cargo      string
+	}{
+		{
+			name:       "Release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, ShortHash: "abcdef12"},
+			npm:        "1.2.3",
+			rubyGems:   "1.2.3",
+			cargo:      "1.2.3",
+		},
+		{
+			name:       "Dirty release",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3"), IsExact: true, Dirty: true, ShortHash: "abcdef12"},
+			npm:        "1.2.3+dirty",
+			rubyGems:   "1.2.3.dirty",
+			cargo:      "1.2.3+dirty",
+		},
+		{
+			name:       "Release candidate tag",
+			components: VersionComponents{Semver: semver.MustParse("1.2.3-rc.2"), IsExact: true, ShortHash: "abcdef12"},
+			opts:       Options{OmitCommitHash: true},
+			npm:        "1.2.3-rc.2",
+			rubyGems:   "1.2.3.rc.2",
+			cargo:      "1.2.3-rc.2",
+		},
+		{
+			name:       "Untagged build",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-alpha"), Timestamp: timestamp, ShortHash: "abcdef12"},
+			npm:        "1.3.0-alpha.1704164645+abcdef12",
+			rubyGems:   "1.3.0.alpha.1704164645",
+			cargo:      "1.3.0-alpha.1704164645+abcdef12",
+		},
+		{
+			name:       "Untagged dirty build without hash",
+			components: VersionComponents{Semver: semver.MustParse("1.3.0-beta"), Timestamp: timestamp, Dirty: true, ShortHash: "abcdef12"},
+			opts:       Options{OmitCommitHash: true},
+			npm:        "1.3.0-beta.1704164645.dirty",
+			rubyGems:   "1.3.0.beta.1704164645.dirty",
+			cargo:      "1.3.0-beta.1704164645.dirty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			versions, err := buildLanguageVersions(&test.components, test.opts)
+			require.NoError(t, err)
+
+			require.Equal(t, test.npm, versions.NPM)
+			require.Equal(t, test.rubyGems, versions.RubyGems)
+			require.Equal(t, test.cargo, versions.Cargo)
+
+			require.Regexp(t, semverGrammarRe, versions.NPM)
+			require.Regexp(t, rubyGemsGrammarRe, versions.RubyGems)
+			require.Regexp(t, semverGrammarRe, versions.Cargo)
+		})
+	}
+}
+
+func TestRegistryVersionsFromString(t *testing.T) {
+	tests := []struct {
+		input    string
+		npm      string
+		rubyGems string
+		cargo    string
+	}{
+		{"1.2.3", "1.2.3", "1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3", "1.2.3", "1.2.3"},
+		{"1.2.3-alpha.1", "1.2.3-alpha.1", "1.2.3.alpha.1", "1.2.3-alpha.1"},
+		{"v2.0.0-rc.1+abcdef12", "2.0.0-rc.1+abcdef12", "2.0.0.rc.1", "2.0.0-rc.1+abcdef12"},
+		{"1.2.3-x-y.1", "1.2.3-x-y.1", "1.2.3.x.y.1", "1.2.3-x-y.1"},
+		{"1.2.3+abcdef12.dirty", "1.2.3+abcdef12.dirty", "1.2.3.dirty", "1.2.3+abcdef12.dirty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			versions, err := CalculateFromString(test.input)
+			require.NoError(t, err)
+
+			require.Equal(t, test.npm, versions.NPM)
+			require.Equal(t, test.rubyGems, versions.RubyGems)
+			require.Equal(t, test.cargo, versions.Cargo)
+
+			require.Regexp(t, semverGrammarRe, versions.NPM)
+			require.Regexp(t, rubyGemsGrammarRe, versions.RubyGems)
+			require.Regexp(t, semverGrammarRe, versions.Cargo)
+		})
+	}
+}
+
+func TestFallbackRegistryVersions(t *testing.T) {
+	versions := GenerateFallbackVersion()
+	require.Regexp(t, semverGrammarRe, versions.NPM)
+	require.Regexp(t, rubyGemsGrammarRe, versions.RubyGems)
+	require.Regexp(t, semverGrammarRe, versions.Cargo)
+}
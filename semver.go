@@ -0,0 +1,140 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// Version is a semantic version together with the Git state it was
+// calculated from. The Git fields are empty for parsed versions.
+type Version struct {
+	semver.Version
+
+	// Hash is the full hash of the commit the version was calculated for
+	Hash string
+
+	// Timestamp is the commit time
+	Timestamp time.Time
+
+	// Dirty reports uncommitted changes in the working tree
+	Dirty bool
+
+	// Distance is the number of commits since the base tag
+	Distance int
+
+	// formats holds the other formats of a calculated version, nil for
+	// parsed and derived versions
+	formats *LanguageVersions
+}
+
+// Parse parses a semantic version, with or without a leading "v"
+func Parse(version string) (Version, error) {
+	parsed, err := semver.Parse(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return Version{}, fmt.Errorf("parsing version %q: %w", version, err)
+	}
+	return Version{Version: parsed}, nil
+}
+
+// newVersion wraps the calculated versions with the components they were
+// built from
+func newVersion(versions *LanguageVersions, components *VersionComponents) (*Version, error) {
+	parsed, err := semver.Parse(versions.SemVer)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculated version %q: %w", versions.SemVer, err)
+	}
+
+	formats := *versions
+	formats.Version = nil
+
+	return &Version{
+		Version:   parsed,
+		Hash:      components.Hash,
+		Timestamp: components.Timestamp,
+		Dirty:     components.Dirty,
+		Distance:  components.Distance,
+		formats:   &formats,
+	}, nil
+}
+
+// Compare returns -1, 0 or 1 as v has lower, equal or higher precedence
+// than o. Build metadata, including the commit hash, is ignored as the
+// semantic versioning specification requires.
+func (v Version) Compare(o Version) int {
+	return v.Version.Compare(o.Version)
+}
+
+// BumpMajor returns the next major version. Pre-releases of a major
+// version are bumped to that version: 2.0.0-rc.1 becomes 2.0.0.
+func (v Version) BumpMajor() Version {
+	next := semver.Version{Major: v.Major + 1}
+	if len(v.Pre) > 0 && v.Minor == 0 && v.Patch == 0 {
+		next.Major = v.Major
+	}
+	return Version{Version: next}
+}
+
+// BumpMinor returns the next minor version. Pre-releases of a minor
+// version are bumped to that version: 1.3.0-alpha.5 becomes 1.3.0.
+func (v Version) BumpMinor() Version {
+	next := semver.Version{Major: v.Major, Minor: v.Minor + 1}
+	if len(v.Pre) > 0 && v.Patch == 0 {
+		next.Minor = v.Minor
+	}
+	return Version{Version: next}
+}
+
+// BumpPatch returns the next patch version. Pre-releases are bumped to
+// their release: 1.2.3-beta.1 becomes 1.2.3.
+func (v Version) BumpPatch() Version {
+	next := semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	if len(v.Pre) > 0 {
+		next.Patch = v.Patch
+	}
+	return Version{Version: next}
+}
+
+// WithPrerelease returns the version with its pre-release replaced by the
+// dot-separated identifiers in pre (e.g., "rc.1") and its build metadata
+// removed. An empty pre returns the release version.
+func (v Version) WithPrerelease(pre string) (Version, error) {
+	next := semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	if pre != "" {
+		for _, part := range strings.Split(pre, ".") {
+			identifier, err := semver.NewPRVersion(part)
+			if err != nil {
+				return Version{}, fmt.Errorf("invalid pre-release %q: %w", pre, err)
+			}
+			next.Pre = append(next.Pre, identifier)
+		}
+	}
+
+	return Version{Version: next}, nil
+}
+
+// Format returns the version in the named format, using the output names
+// listed by Outputs (e.g., "python", "maven" or "helm-app-version").
+// Calculated versions are formatted with the options they were calculated
+// with; other versions are converted as by CalculateFromString.
+func (v Version) Format(name string) (string, error) {
+	versions := v.formats
+	if versions == nil {
+		var err error
+		versions, err = CalculateFromString(v.String())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, output := range Outputs(versions, nil) {
+		if output.Name == name {
+			return output.Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown format %q", name)
+}
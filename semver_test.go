@@ -0,0 +1,181 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("With v prefix", func(t *testing.T) {
+		version, err := Parse("v1.2.3-rc.1+abcdef12")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), version.Major)
+		require.Equal(t, "1.2.3-rc.1+abcdef12", version.String())
+		require.Empty(t, version.Hash)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := Parse("1.2")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parsing version \"1.2\"")
+	})
+}
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.2.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, err := Parse(ordered[i])
+			require.NoError(t, err)
+			b, err := Parse(ordered[j])
+			require.NoError(t, err)
+
+			require.Equal(t, compareInt(i, j), a.Compare(b), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+
+	t.Run("Build metadata is ignored", func(t *testing.T) {
+		a, _ := Parse("1.3.0-alpha.1+abcdef12")
+		b, _ := Parse("1.3.0-alpha.1+12345678.dirty")
+		require.Equal(t, 0, a.Compare(b))
+	})
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		version string
+		major   string
+		minor   string
+		patch   string
+	}{
+		{"1.2.3", "2.0.0", "1.3.0", "1.2.4"},
+		{"1.2.3+abcdef12", "2.0.0", "1.3.0", "1.2.4"},
+		{"1.3.0-alpha.1704164645+abcdef12", "2.0.0", "1.3.0", "1.3.0"},
+		{"1.2.3-beta.1", "2.0.0", "1.3.0", "1.2.3"},
+		{"2.0.0-rc.1", "2.0.0", "2.0.0", "2.0.0"},
+		{"0.0.0", "1.0.0", "0.1.0", "0.0.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			version, err := Parse(test.version)
+			require.NoError(t, err)
+
+			require.Equal(t, test.major, version.BumpMajor().String())
+			require.Equal(t, test.minor, version.BumpMinor().String())
+			require.Equal(t, test.patch, version.BumpPatch().String())
+		})
+	}
+}
+
+func TestVersionWithPrerelease(t *testing.T) {
+	version, err := Parse("1.3.0-alpha.5+abcdef12")
+	require.NoError(t, err)
+
+	rc, err := version.WithPrerelease("rc.1")
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0-rc.1", rc.String())
+
+	release, err := version.WithPrerelease("")
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", release.String())
+
+	_, err = version.WithPrerelease("rc.01")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid pre-release")
+}
+
+func TestVersionFormat(t *testing.T) {
+	t.Run("Calculated version", func(t *testing.T) {
+		components := &VersionComponents{
+			Semver:    semver.MustParse("1.3.0-alpha"),
+			Hash:      "abcdef1234567890abcdef1234567890abcdef12",
+			ShortHash: "abcdef12",
+			Timestamp: time.Unix(1704164645, 0),
+			Distance:  7,
+		}
+
+		versions, err := buildLanguageVersions(components, Options{MavenSnapshot: MavenSnapshotTimestamp})
+		require.NoError(t, err)
+		require.NotNil(t, versions.Version)
+
+		version := *versions.Version
+		require.Equal(t, "1.3.0-alpha.1704164645+abcdef12", version.String())
+		require.Equal(t, components.Hash, version.Hash)
+		require.Equal(t, 7, version.Distance)
+
+		// Formats keep the options the version was calculated with
+		maven, err := version.Format("maven")
+		require.NoError(t, err)
+		require.Equal(t, versions.Maven, maven)
+		require.Equal(t, "1.3.0-20240102.030405-7", maven)
+
+		python, err := version.Format("python")
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0a1704164645+gabcdef12", python)
+	})
+
+	t.Run("Parsed version", func(t *testing.T) {
+		version, err := Parse("1.2.3-rc.1")
+		require.NoError(t, err)
+
+		python, err := version.Format("python")
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3rc1", python)
+
+		next := version.BumpMinor()
+		debian, err := next.Format("debian")
+		require.NoError(t, err)
+		require.Equal(t, "1.3.0", debian)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		version, _ := Parse("1.2.3")
+		_, err := version.Format("cobol")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown format")
+	})
+}
+
+func TestCalculateVersion(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoSingleCommitPastRelease(repo)
+	require.NoError(t, err)
+
+	versions, err := Calculate(Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+	require.NoError(t, err)
+	require.NotNil(t, versions.Version)
+	require.Equal(t, versions.SemVer, versions.Version.String())
+	require.Equal(t, 1, versions.Version.Distance)
+	require.NotEmpty(t, versions.Version.Hash)
+
+	release, err := Parse("1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, 1, versions.Version.Compare(release))
+	require.Equal(t, "1.1.0", versions.Version.BumpPatch().String())
+
+	t.Run("Converted and fallback versions", func(t *testing.T) {
+		converted, err := CalculateFromString("v1.2.3")
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3", converted.Version.String())
+
+		require.Equal(t, "0.0.0-dev", GenerateFallbackVersion().Version.String())
+	})
+}
@@ -0,0 +1,105 @@
+package vers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Tag is a tag vers can use as a base version
+type Tag struct {
+	Name    string         `json:"name"`
+	Version semver.Version `json:"version"`
+
+	// Commit is the full hash of the tagged commit and Date its commit time
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+
+	// Annotated distinguishes annotated from lightweight tags
+	Annotated  bool `json:"annotated"`
+	Prerelease bool `json:"prerelease"`
+}
+
+// ListTags returns the tags of opts.Repository that vers considers when
+// calculating versions, sorted by semantic version precedence. Tags are
+// filtered by TagFilter or TagPattern and by Constraint, beta and rc tags
+// are only included with IsPreRelease, and tags that are not versions are
+// skipped.
+func ListTags(opts Options) ([]Tag, error) {
+	if opts.Repository == nil {
+		return nil, fmt.Errorf("repository is required")
+	}
+
+	if err := applyTagFilters(&opts); err != nil {
+		return nil, err
+	}
+
+	refs, err := opts.Repository.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+
+	var tags []Tag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !tagAllowed(ref.Name().String(), opts.IsPreRelease, opts.TagFilter) {
+			return nil
+		}
+
+		version, err := parseTagVersion(ref.Name().Short())
+		if err != nil {
+			return nil
+		}
+
+		hash, err := tagCommitHash(opts.Repository, ref)
+		if err != nil {
+			return err
+		}
+		commit, err := opts.Repository.CommitObject(hash)
+		if err != nil {
+			return fmt.Errorf("resolving tag %s: %w", ref.Name().Short(), err)
+		}
+
+		tags = append(tags, Tag{
+			Name:       ref.Name().Short(),
+			Version:    version,
+			Commit:     hash.String(),
+			Date:       commit.Committer.When,
+			Annotated:  hash != ref.Hash(),
+			Prerelease: len(version.Pre) > 0,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if c := tags[i].Version.Compare(tags[j].Version); c != 0 {
+			return c < 0
+		}
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+// FilterTags returns the tags whose versions satisfy a range such as
+// ">=1.2.0 <2.0.0", "<1.0.0 || >=2.0.0" or "~1.4"
+func FilterTags(tags []Tag, versionRange string) ([]Tag, error) {
+	inRange, err := parseConstraint(versionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []Tag
+	for _, tag := range tags {
+		if inRange(tag.Version) {
+			filtered = append(filtered, tag)
+		}
+	}
+
+	return filtered, nil
+}
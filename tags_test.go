@@ -0,0 +1,138 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepoReleaseHistory creates a repository with one commit per tag,
+// annotating the tags in annotated
+func testRepoReleaseHistory(t *testing.T, tags []string, annotated map[string]bool) *git.Repository {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for _, tag := range tags {
+		addFile(t, workTree, "file.txt", tag)
+		hash, err := workTree.Commit("Commit for "+tag, &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+
+		var opts *git.CreateTagOptions
+		if annotated[tag] {
+			opts = &git.CreateTagOptions{Tagger: testSignature, Message: "Release " + tag}
+		}
+		_, err = repo.CreateTag(tag, hash, opts)
+		require.NoError(t, err)
+	}
+
+	return repo
+}
+
+func tagNames(tags []Tag) []string {
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestListTags(t *testing.T) {
+	repo := testRepoReleaseHistory(t,
+		[]string{"v1.0.0", "v1.10.0", "v1.2.0-alpha.1", "v1.2.0", "nightly", "v2.0.0-rc.1", "sdk/v0.5.0"},
+		map[string]bool{"v1.2.0": true})
+
+	t.Run("Sorted by version", func(t *testing.T) {
+		tags, err := ListTags(Options{Repository: repo})
+		require.NoError(t, err)
+		require.Equal(t, []string{"sdk/v0.5.0", "v1.0.0", "v1.2.0-alpha.1", "v1.2.0", "v1.10.0"}, tagNames(tags))
+	})
+
+	t.Run("Constraint", func(t *testing.T) {
+		tags, err := ListTags(Options{Repository: repo, Constraint: "^1.2"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"v1.2.0", "v1.10.0"}, tagNames(tags))
+	})
+
+	t.Run("Tag details", func(t *testing.T) {
+		tags, err := ListTags(Options{Repository: repo})
+		require.NoError(t, err)
+
+		alpha, release := tags[2], tags[3]
+		require.Equal(t, "1.2.0-alpha.1", alpha.Version.String())
+		require.True(t, alpha.Prerelease)
+		require.False(t, alpha.Annotated)
+
+		require.True(t, release.Annotated)
+		require.False(t, release.Prerelease)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+		commit, err := repo.CommitObject(head.Hash())
+		require.NoError(t, err)
+		parent, err := commit.Parent(0)
+		require.NoError(t, err)
+		parent, err = parent.Parent(0)
+		require.NoError(t, err)
+		parent, err = parent.Parent(0)
+		require.NoError(t, err)
+		require.Equal(t, parent.Hash.String(), release.Commit)
+		require.Equal(t, parent.Committer.When.Unix(), release.Date.Unix())
+	})
+
+	t.Run("Pre-release tags", func(t *testing.T) {
+		tags, err := ListTags(Options{Repository: repo, IsPreRelease: true})
+		require.NoError(t, err)
+		require.Equal(t, "v2.0.0-rc.1", tags[len(tags)-1].Name)
+	})
+
+	t.Run("Tag pattern", func(t *testing.T) {
+		tags, err := ListTags(Options{Repository: repo, TagPattern: "^sdk/"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"sdk/v0.5.0"}, tagNames(tags))
+	})
+
+	t.Run("Invalid tag pattern", func(t *testing.T) {
+		_, err := ListTags(Options{Repository: repo, TagPattern: "("})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid tag pattern")
+	})
+
+	t.Run("No repository", func(t *testing.T) {
+		_, err := ListTags(Options{})
+		require.Error(t, err)
+	})
+}
+
+func TestFilterTags(t *testing.T) {
+	repo := testRepoReleaseHistory(t, []string{"v1.0.0", "v1.2.0", "v1.5.0", "v2.0.0", "v2.1.0"}, nil)
+	tags, err := ListTags(Options{Repository: repo})
+	require.NoError(t, err)
+
+	tests := []struct {
+		versionRange string
+		expected     []string
+	}{
+		{">=1.2.0 <2.0.0", []string{"v1.2.0", "v1.5.0"}},
+		{"<1.2.0 || >=2.1.0", []string{"v1.0.0", "v2.1.0"}},
+		{"~1.5 || ^2.1", []string{"v1.5.0", "v2.1.0"}},
+		{">3.0.0", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.versionRange, func(t *testing.T) {
+			filtered, err := FilterTags(tags, test.versionRange)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, tagNames(filtered))
+		})
+	}
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := FilterTags(tags, ">=one")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid version constraint")
+	})
+}
@@ -0,0 +1,177 @@
+package vers
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// TemplateData is the data model available to version templates. It embeds
+// LanguageVersions, so every language format is available by field name,
+// e.g. {{.Python}}.
+type TemplateData struct {
+	LanguageVersions
+
+	Major uint64
+	Minor uint64
+	Patch uint64
+
+	// Prerelease is the full pre-release identifier, e.g. "alpha.1704164645"
+	Prerelease string
+
+	// PrereleaseLabel is the first pre-release part, e.g. "alpha"
+	PrereleaseLabel string
+
+	// PrereleaseNumber is the numeric pre-release part, e.g. "1704164645"
+	PrereleaseNumber string
+
+	// PrereleaseParts lists every dot-separated pre-release part
+	PrereleaseParts []string
+
+	Hash        string
+	ShortHash   string
+	Timestamp   time.Time
+	Branch      string
+	PullRequest string
+	BaseTag     string
+	Distance    int
+	Dirty       bool
+	IsExact     bool
+}
+
+// templateFuncs are the helper functions available to version templates.
+// Values come last so the helpers work in pipelines, e.g.
+// {{.Distance | pad 4}} or {{.Timestamp | date "20060102"}}.
+var templateFuncs = template.FuncMap{
+	// pad left-pads a value with zeros to the given width
+	"pad": func(width int, value interface{}) string {
+		return padLeft(fmt.Sprint(value), width, "0")
+	},
+	// padLeft left-pads a value with spaces to the given width
+	"padLeft": func(width int, value interface{}) string {
+		return padLeft(fmt.Sprint(value), width, " ")
+	},
+	// padRight right-pads a value with spaces to the given width
+	"padRight": func(width int, value interface{}) string {
+		s := fmt.Sprint(value)
+		if len(s) >= width {
+			return s
+		}
+		return s + strings.Repeat(" ", width-len(s))
+	},
+	// trunc keeps the first n characters, or the last -n when n is negative
+	"trunc": func(n int, value interface{}) string {
+		s := fmt.Sprint(value)
+		switch {
+		case n >= 0 && n < len(s):
+			return s[:n]
+		case n < 0 && -n < len(s):
+			return s[len(s)+n:]
+		default:
+			return s
+		}
+	},
+	// date formats a time in UTC using a Go reference layout
+	"date": func(layout string, t time.Time) string {
+		return t.UTC().Format(layout)
+	},
+	// unix formats a time as seconds since the epoch
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// replace replaces every occurrence of old with new
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+}
+
+// NewTemplateData builds the template data model. Components may be nil for
+// converted or fallback versions, in which case the numeric fields are
+// parsed from the SemVer string.
+func NewTemplateData(versions *LanguageVersions, components *VersionComponents) (*TemplateData, error) {
+	data := &TemplateData{LanguageVersions: *versions}
+
+	if components == nil {
+		version, err := semver.Parse(versions.SemVer)
+		if err != nil {
+			return nil, fmt.Errorf("parsing version %q: %w", versions.SemVer, err)
+		}
+		data.Major, data.Minor, data.Patch = version.Major, version.Minor, version.Patch
+		data.IsExact = true
+		for _, pre := range version.Pre {
+			data.PrereleaseParts = append(data.PrereleaseParts, pre.String())
+		}
+	} else {
+		data.Major = components.Semver.Major
+		data.Minor = components.Semver.Minor
+		data.Patch = components.Semver.Patch
+		data.PrereleaseParts = prereleaseParts(components)
+		data.Hash = components.Hash
+		data.ShortHash = components.ShortHash
+		data.Timestamp = components.Timestamp
+		data.Branch = components.Branch
+		data.PullRequest = components.PullRequest
+		data.BaseTag = components.BaseTag
+		data.Distance = components.Distance
+		data.Dirty = components.Dirty
+		data.IsExact = components.IsExact
+	}
+
+	data.Prerelease = strings.Join(data.PrereleaseParts, ".")
+	if len(data.PrereleaseParts) > 0 {
+		data.PrereleaseLabel = data.PrereleaseParts[0]
+	}
+	if len(data.PrereleaseParts) > 1 {
+		data.PrereleaseNumber = data.PrereleaseParts[1]
+	}
+
+	return data, nil
+}
+
+// RenderTemplate renders a Go text/template against the data model
+// described by TemplateData
+func RenderTemplate(text string, versions *LanguageVersions, components *VersionComponents) (string, error) {
+	tmpl, err := template.New("version").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+
+	data, err := NewTemplateData(versions, components)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("rendering template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// prereleaseParts lists the pre-release parts of the calculated version,
+// including the number appended to builds past a tag
+func prereleaseParts(components *VersionComponents) []string {
+	var parts []string
+	for _, pre := range components.Semver.Pre {
+		parts = append(parts, pre.String())
+	}
+
+	if len(parts) == 1 && !components.verbatim() {
+		parts = append(parts, prereleaseNumber(components))
+	}
+
+	return parts
+}
+
+func padLeft(s string, width int, pad string) string {
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat(pad, width-len(s)) + s
+}
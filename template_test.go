@@ -0,0 +1,74 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	versions := &LanguageVersions{
+		SemVer:     "1.3.0-alpha.1704164645+abcdef12",
+		Python:     "1.3.0a1704164645",
+		JavaScript: "v1.3.0-alpha.1704164645+abcdef12",
+		DotNet:     "1.3.0-alpha.1704164645+abcdef12",
+		Go:         "v1.3.0-alpha.1704164645+abcdef12",
+	}
+	components := &VersionComponents{
+		Semver:    semver.MustParse("1.3.0-alpha"),
+		Hash:      "abcdef1234567890abcdef1234567890abcdef12",
+		ShortHash: "abcdef12",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		BaseTag:   "v1.2.0",
+		Distance:  7,
+		Branch:    "Feature/Login",
+		Dirty:     true,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"Components", "myapp_{{.Major}}.{{.Minor}}.{{.Patch}}_linux", "myapp_1.3.0_linux"},
+		{"Four-part version", "{{.Major}}.{{.Minor}}.{{.Patch}}.{{.Distance}}", "1.3.0.7"},
+		{"Language versions", "{{.Python}} {{.Go}}", "1.3.0a1704164645 v1.3.0-alpha.1704164645+abcdef12"},
+		{"Pre-release parts", "{{.Prerelease}}|{{.PrereleaseLabel}}|{{.PrereleaseNumber}}", "alpha.1704164645|alpha|1704164645"},
+		{"Hashes", "{{.Hash | trunc 12}} {{.ShortHash}} {{.Hash | trunc -4}}", "abcdef123456 abcdef12 ef12"},
+		{"Padding", "[{{.Distance | pad 4}}][{{.Minor | padLeft 3}}][{{.Patch | padRight 3}}]", "[0007][  3][0  ]"},
+		{"Dates", "{{.Timestamp | date \"20060102.150405\"}} {{.Timestamp | unix}}", "20240102.030405 1704164645"},
+		{"Strings", "{{.Branch | lower | replace \"/\" \"-\"}} {{.BaseTag | upper}}", "feature-login V1.2.0"},
+		{"Conditionals", "{{if .Dirty}}dirty{{else}}clean{{end}} {{if .IsExact}}release{{else}}snapshot{{end}}", "dirty snapshot"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := RenderTemplate(test.template, versions, components)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, output)
+		})
+	}
+
+	t.Run("Without components", func(t *testing.T) {
+		converted, err := CalculateFromString("2.1.0-rc.3")
+		require.NoError(t, err)
+
+		output, err := RenderTemplate("{{.Major}}-{{.Minor}}-{{.Patch}}-{{.PrereleaseLabel}}{{.PrereleaseNumber}} {{.IsExact}}", converted, nil)
+		require.NoError(t, err)
+		require.Equal(t, "2-1-0-rc3 true", output)
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		_, err := RenderTemplate("{{.Major", versions, components)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parsing template")
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		_, err := RenderTemplate("{{.Nope}}", versions, components)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rendering template")
+	})
+}
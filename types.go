@@ -19,6 +19,32 @@ type LanguageVersions struct {
 	JavaScript string `json:"javascript"`
 	DotNet     string `json:"dotnet"`
 	Go         string `json:"go"`
+	Maven      string `json:"maven"`
+	Debian     string `json:"debian"`
+	RPM        string `json:"rpm"`
+	RPMRelease string `json:"rpm_release"`
+	Docker     string `json:"docker"`
+	NPM        string `json:"npm"`
+	RubyGems   string `json:"rubygems"`
+	Cargo      string `json:"cargo"`
+
+	// Helm is the Chart.yaml version and HelmAppVersion its appVersion,
+	// which matches the container image tag charts default to
+	Helm           string `json:"helm"`
+	HelmAppVersion string `json:"helm_app_version"`
+
+	// Version is the semantic version with the Git state it was calculated
+	// from, for comparing and bumping. It is nil for converted versions
+	// that are not valid semantic versions.
+	Version *Version `json:"-"`
+
+	// .NET sub-formats: AssemblyVersion, FileVersion, InformationalVersion
+	// and the SemVer 1.0 version required by legacy NuGet clients.
+	// DotNetAssembly and DotNetFile are empty when a part exceeds 65535.
+	DotNetAssembly      string `json:"dotnet_assembly"`
+	DotNetFile          string `json:"dotnet_file"`
+	DotNetInformational string `json:"dotnet_informational"`
+	NuGetV1             string `json:"nuget_v1"`
 }
 
 // Options configures version calculation behavior
@@ -26,6 +52,10 @@ type Options struct {
 	// Repository is the Git repository to analyze
 	Repository *git.Repository
 
+	// Archival is used instead of Repository when building from a source
+	// archive without a .git directory (see ReadArchival)
+	Archival *Archival
+
 	// Commitish specifies which commit to analyze (default: "HEAD")
 	Commitish plumbing.Revision
 
@@ -43,6 +73,58 @@ type Options struct {
 
 	// TagPattern is a regex pattern to filter tags (alternative to TagFilter)
 	TagPattern string
+
+	// ModuleDir is the directory of the Go module being versioned in a
+	// multi-module repository. Unless TagPattern or TagFilter is set, only
+	// the module's tags (e.g., sdk/v1.2.3) are considered.
+	ModuleDir string
+
+	// StrictGoModule fails version calculation when the go.mod module path
+	// doesn't match the major version, instead of adding a warning
+	StrictGoModule bool
+
+	// APIBump picks the increment for builds past a tag from the changes to
+	// the exported Go API since the tag, as reported by DiffAPI
+	APIBump bool
+
+	// Constraint limits base versions to a range such as ">=1.0.0 <2.0.0"
+	// or "~1.4". Builds past a tag are bumped by patch instead of minor
+	// when the minor bump would leave the range.
+	Constraint string
+
+	// Override forces the reported version (e.g., "1.2.3"), bypassing tag
+	// analysis. It is reported exactly as given and needs no Repository.
+	Override string
+
+	// PrereleaseLabel replaces "alpha" for builds past a tag (dev, alpha, beta or rc)
+	PrereleaseLabel string
+
+	// BuildNumber replaces the commit timestamp in pre-release versions
+	BuildNumber string
+
+	// MavenSnapshot selects how untagged builds are formatted for Maven:
+	// MavenSnapshotPlain (default) or MavenSnapshotTimestamp
+	MavenSnapshot string
+
+	// PackageEpoch is prepended to Debian versions as "epoch:". RPM epochs
+	// are a separate spec field and are not included.
+	PackageEpoch string
+
+	// PackageRevision is appended to Debian versions as "-revision" and used
+	// as the RPM Release (default "1")
+	PackageRevision string
+
+	// FileVersionRevision selects the fourth part of the .NET FileVersion:
+	// FileVersionRevisionDistance (default), FileVersionRevisionBuildNumber
+	// or FileVersionRevisionZero
+	FileVersionRevision string
+
+	// HelmBuildMetadata selects how the Helm chart version treats build
+	// metadata: HelmBuildMetadataKeep (default) or HelmBuildMetadataStrip
+	HelmBuildMetadata string
+
+	// CI is the build context reported by a CI provider (see DetectCI)
+	CI *CIContext
 }
 
 // VersionComponents contains the raw components used for version calculation
@@ -52,4 +134,38 @@ type VersionComponents struct {
 	ShortHash string
 	Timestamp time.Time
 	IsExact   bool
+
+	// Hash is the full hash of the commit being versioned
+	Hash string
+
+	// BaseTag is the tag the version was derived from, empty if none was found
+	BaseTag string
+
+	// Distance is the number of commits since BaseTag, or since the root
+	// commit when there is no tag
+	Distance int
+
+	// Branch is the branch being built, empty for detached checkouts outside CI
+	Branch string
+
+	// PullRequest is the pull request number reported by CI, if any
+	PullRequest string
+
+	// BuildNumber replaces the commit timestamp in pre-release versions when set
+	BuildNumber string
+
+	// Overrides describes each override applied to the calculated version
+	Overrides []string
+
+	// Forced reports that Options.Override replaced the calculated version.
+	// BaseTag and IsExact still describe the tag lookup.
+	Forced bool
+
+	// APIReport lists the exported Go API changes since BaseTag when
+	// Options.APIBump is set
+	APIReport *APIReport
+
+	// Warnings describes problems that don't prevent publishing the
+	// version, such as a go.mod module path without its major version
+	Warnings []string
 }
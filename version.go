@@ -5,6 +5,7 @@
 package vers
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -16,36 +17,126 @@ import (
 // Calculate determines version strings for multiple language ecosystems
 // based on Git repository state and tags
 func Calculate(opts Options) (*LanguageVersions, error) {
-	if opts.Repository == nil {
-		return nil, fmt.Errorf("repository is required")
+	return CalculateContext(context.Background(), opts)
+}
+
+// CalculateContext is like Calculate but stops walking the commit history
+// and kills git subprocesses when ctx is done, returning ctx.Err()
+func CalculateContext(ctx context.Context, opts Options) (*LanguageVersions, error) {
+	versions, _, err := CalculateWithComponentsContext(ctx, opts)
+	return versions, err
+}
+
+// CalculateWithComponents is like Calculate but also returns the components
+// the versions were built from, including the base tag and applied overrides
+func CalculateWithComponents(opts Options) (*LanguageVersions, *VersionComponents, error) {
+	return CalculateWithComponentsContext(context.Background(), opts)
+}
+
+// CalculateWithComponentsContext is like CalculateWithComponents but can be
+// cancelled or time-limited through ctx
+func CalculateWithComponentsContext(ctx context.Context, opts Options) (*LanguageVersions, *VersionComponents, error) {
+	// A forced version needs no history to describe
+	if opts.Repository == nil && opts.Archival == nil && opts.Override == "" {
+		return nil, nil, fmt.Errorf("repository is required")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateOverrides(opts); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateMavenSnapshot(opts.MavenSnapshot); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validatePackageOptions(opts); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateFileVersionRevision(opts.FileVersionRevision); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateHelmBuildMetadata(opts.HelmBuildMetadata); err != nil {
+		return nil, nil, err
 	}
 
 	if opts.Commitish == "" {
 		opts.Commitish = "HEAD"
 	}
 
-	// Apply tag pattern filter if specified
+	if err := applyTagFilters(&opts); err != nil {
+		return nil, nil, err
+	}
+
+	components, err := getVersionComponents(ctx, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("calculating version components: %w", err)
+	}
+
+	versions, err := buildLanguageVersions(components, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return versions, components, nil
+}
+
+// applyTagFilters sets TagFilter from TagPattern, or the tag prefix of
+// ModuleDir, unless a filter was given, then narrows it to tags whose
+// versions satisfy Constraint
+func applyTagFilters(opts *Options) error {
+	if opts.ModuleDir != "" && opts.TagPattern == "" && opts.TagFilter == nil {
+		opts.TagPattern = ModuleTagPattern(opts.ModuleDir)
+	}
+
 	if opts.TagPattern != "" && opts.TagFilter == nil {
 		re, err := regexp.Compile(opts.TagPattern)
 		if err != nil {
-			return nil, fmt.Errorf("invalid tag pattern: %w", err)
+			return fmt.Errorf("invalid tag pattern: %w", err)
 		}
 		opts.TagFilter = func(tag string) bool {
 			return re.MatchString(tag)
 		}
 	}
 
-	components, err := getVersionComponents(opts)
-	if err != nil {
-		return nil, fmt.Errorf("calculating version components: %w", err)
+	if opts.Constraint != "" {
+		inRange, err := parseConstraint(opts.Constraint)
+		if err != nil {
+			return err
+		}
+
+		filter := opts.TagFilter
+		opts.TagFilter = func(tag string) bool {
+			if filter != nil && !filter(tag) {
+				return false
+			}
+			version, err := parseTagVersion(tag)
+			return err == nil && inRange(version)
+		}
 	}
 
-	return buildLanguageVersions(components, opts)
+	return nil
 }
 
 // CalculateFromString parses an existing version string and converts it
-// to different language-specific formats
+// to different language-specific formats, detecting PEP 440, Debian and Go
+// pseudo-versions (see ConvertString)
 func CalculateFromString(version string) (*LanguageVersions, error) {
+	conversion, err := ConvertString(version, SourceAuto)
+	if err != nil {
+		return nil, err
+	}
+	return conversion.Versions, nil
+}
+
+// fromSemVerString converts a semantic version string, best effort for
+// versions blang/semver rejects
+func fromSemVerString(version string) (*LanguageVersions, error) {
 	// Strip leading "v" if present
 	normalised := strings.TrimPrefix(version, "v")
 
@@ -57,16 +148,25 @@ func CalculateFromString(version string) (*LanguageVersions, error) {
 	major, minor := parts[0], parts[1]
 	patch := parts[2]
 
-	pythonPatch, err := convertPatchToPython(patch)
+	genericVersion := fmt.Sprintf("%s.%s.%s", major, minor, patch)
+
+	pythonVersion, err := pythonFromString(genericVersion)
 	if err != nil {
-		return nil, fmt.Errorf("converting patch for Python: %w", err)
+		return nil, fmt.Errorf("converting version for Python: %w", err)
 	}
 
-	genericVersion := fmt.Sprintf("%s.%s.%s", major, minor, patch)
-	pythonVersion := fmt.Sprintf("%s.%s.%s", major, minor, pythonPatch)
 	jsVersion := fmt.Sprintf("v%s", genericVersion)
 	dotnetVersion := genericVersion
 	goVersion := fmt.Sprintf("v%s", genericVersion)
+	dotnet := dotnetFromString(genericVersion)
+	registries := registryFromString(genericVersion)
+
+	// Versions blang/semver rejects, e.g. with leading zeros, are converted
+	// without a Version
+	var parsed *Version
+	if v, err := Parse(genericVersion); err == nil {
+		parsed = &v
+	}
 
 	return &LanguageVersions{
 		SemVer:     genericVersion,
@@ -74,182 +174,133 @@ func CalculateFromString(version string) (*LanguageVersions, error) {
 		JavaScript: jsVersion,
 		DotNet:     dotnetVersion,
 		Go:         goVersion,
+		Maven:      mavenFromString(genericVersion),
+		Debian:     packageFromString(genericVersion),
+		RPM:        packageFromString(genericVersion),
+		RPMRelease: defaultRPMRelease,
+		Docker:     DockerTag(genericVersion),
+		NPM:        registries.npm,
+		RubyGems:   registries.rubyGems,
+		Cargo:      registries.cargo,
+
+		Helm:           genericVersion,
+		HelmAppVersion: DockerTag(genericVersion),
+
+		Version: parsed,
+
+		DotNetAssembly:      dotnet.assembly,
+		DotNetFile:          dotnet.file,
+		DotNetInformational: dotnet.informational,
+		NuGetV1:             dotnet.nugetV1,
 	}, nil
 }
 
 func buildLanguageVersions(components *VersionComponents, opts Options) (*LanguageVersions, error) {
-	// Build generic semantic version
-	genericVersion := semver.Version{
-		Major: components.Semver.Major,
-		Minor: components.Semver.Minor,
-		Patch: components.Semver.Patch,
-		Build: components.Semver.Build,
-	}
-
-	// Handle pre-release versions
-	if len(components.Semver.Pre) == 1 {
-		genericVersion.Pre = []semver.PRVersion{
-			components.Semver.Pre[0],
-			{VersionStr: strconv.FormatInt(components.Timestamp.UTC().Unix(), 10)},
-		}
-	} else if len(components.Semver.Pre) > 1 {
-		genericVersion.Pre = components.Semver.Pre
-	}
-
 	// Build version strings for each language
 	baseVersion := fmt.Sprintf("%d.%d.%d",
-		genericVersion.Major, genericVersion.Minor, genericVersion.Patch)
+		components.Semver.Major, components.Semver.Minor, components.Semver.Patch)
 
-	preVersion, pythonPreVersion, err := buildPreVersionStrings(
-		genericVersion, components, opts)
+	preVersion, err := buildPreVersionString(components, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	// Forced versions keep their build metadata
+	if components.Forced && len(components.Semver.Build) > 0 {
+		preVersion += "+" + strings.Join(components.Semver.Build, ".")
+	}
+
 	// Add dirty suffix if needed
 	if components.Dirty {
 		separator := "."
 		if preVersion == "" {
 			separator = "+"
 		}
-		pythonPreVersion += "+dirty"
 		preVersion += separator + "dirty"
 	}
 
 	// Build final versions
 	version := baseVersion + preVersion
-	pythonVersion := baseVersion + pythonPreVersion
 	jsVersion := "v" + version
 	dotnetVersion := version
 	goVersion := "v" + version
+	packageVersion := packageUpstreamVersion(components, opts)
 
-	return &LanguageVersions{
+	dotnet, err := buildDotNetVersions(components, opts)
+	if err != nil {
+		return nil, err
+	}
+	if dotnet.warning != "" {
+		components.Warnings = append(components.Warnings, dotnet.warning)
+	}
+	registries := buildRegistryVersions(version, components)
+
+	versions := &LanguageVersions{
 		SemVer:     version,
-		Python:     pythonVersion,
+		Python:     pythonVersion(components, opts).String(),
 		JavaScript: jsVersion,
 		DotNet:     dotnetVersion,
 		Go:         goVersion,
-	}, nil
-}
-
-func buildPreVersionStrings(genericVersion semver.Version, components *VersionComponents, opts Options) (string, string, error) {
-	if len(genericVersion.Pre) == 0 {
-		return "", "", nil
+		Maven:      mavenVersion(components, opts.MavenSnapshot),
+		Debian:     debianVersion(packageVersion, opts),
+		RPM:        packageVersion,
+		RPMRelease: rpmRelease(opts),
+		Docker:     DockerTag(version),
+		NPM:        registries.npm,
+		RubyGems:   registries.rubyGems,
+		Cargo:      registries.cargo,
+
+		Helm:           helmChartVersion(version, components, opts),
+		HelmAppVersion: DockerTag(version),
+
+		DotNetAssembly:      dotnet.assembly,
+		DotNetFile:          dotnet.file,
+		DotNetInformational: dotnet.informational,
+		NuGetV1:             dotnet.nugetV1,
 	}
 
-	var preSuffix string
-	if !components.IsExact {
-		preSuffix = fmt.Sprintf(".%d", components.Timestamp.UTC().Unix())
-	} else if len(genericVersion.Pre) > 1 {
-		preSuffix = fmt.Sprintf(".%d", genericVersion.Pre[1].VersionNum)
+	versions.Version, err = newVersion(versions, components)
+	if err != nil {
+		return nil, err
 	}
 
-	pythonPreSuffix := "0"
-	if preSuffix != "" {
-		pythonPreSuffix = preSuffix[1:] // Remove leading "."
+	return versions, nil
+}
+
+func buildPreVersionString(components *VersionComponents, opts Options) (string, error) {
+	parts := prereleaseParts(components)
+	if len(parts) == 0 {
+		return "", nil
 	}
 
 	shortHash := ""
-	if !opts.OmitCommitHash && !opts.IsPreRelease {
+	if !opts.OmitCommitHash && !opts.IsPreRelease && opts.Override == "" {
 		shortHash = fmt.Sprintf("+%s", components.ShortHash)
 	}
 
-	preType := genericVersion.Pre[0].VersionStr
+	preType := parts[0]
 
-	var preVersion, pythonPreVersion string
 	switch preType {
-	case "dev":
-		pythonPreVersion = fmt.Sprintf("dev%s", pythonPreSuffix)
-		preVersion = fmt.Sprintf("-dev%s%s", preSuffix, shortHash)
-	case "alpha":
-		pythonPreVersion = fmt.Sprintf("a%s", pythonPreSuffix)
-		preVersion = fmt.Sprintf("-alpha%s%s", preSuffix, shortHash)
-	case "beta":
-		pythonPreVersion = fmt.Sprintf("b%s", pythonPreSuffix)
-		preVersion = fmt.Sprintf("-beta%s%s", preSuffix, shortHash)
-	case "rc":
-		pythonPreVersion = fmt.Sprintf("rc%s", pythonPreSuffix)
-		preVersion = fmt.Sprintf("-rc%s%s", preSuffix, shortHash)
+	case "dev", "alpha", "beta", "rc":
+		return fmt.Sprintf("-%s%s", strings.Join(parts, "."), shortHash), nil
 	default:
-		return "", "", fmt.Errorf("invalid prerelease type: %q", preType)
-	}
-
-	return preVersion, pythonPreVersion, nil
-}
-
-// convertPatchToPython converts a patch version to Python-compatible format
-func convertPatchToPython(patch string) (string, error) {
-	re := regexp.MustCompile(`^(\d+)(.*)$`)
-	matches := re.FindStringSubmatch(patch)
-	if len(matches) != 3 {
-		return patch, nil
-	}
-
-	version := matches[1]
-	pre := matches[2]
-
-	pythonPre, err := getPythonPreVersion(pre)
-	if err != nil {
-		return "", err
+		return "", fmt.Errorf("invalid prerelease type: %q", preType)
 	}
-
-	return version + pythonPre, nil
 }
 
-func getPythonPreVersion(preVersion string) (string, error) {
-	if preVersion == "" {
-		return "", nil
-	}
-
-	prefix, remaining := getPythonPrePrefix(preVersion)
-	isDirty := strings.Contains(preVersion, "dirty")
-
-	if isDirty {
-		remaining = strings.Replace(remaining, "dirty", "", 1)
+// prereleaseNumber is the number following the pre-release label of builds
+// past a tag: the build number if one was given, otherwise the commit timestamp
+func prereleaseNumber(components *VersionComponents) string {
+	if components.BuildNumber != "" {
+		return components.BuildNumber
 	}
-
-	// Remove hash to avoid confusion with build numbers
-	hashRe := regexp.MustCompile(`\+[0-9a-f]{8}\b`)
-	hashMatches := hashRe.FindAllString(remaining, 5)
-	if len(hashMatches) > 0 {
-		shortHash := hashMatches[len(hashMatches)-1]
-		remaining = strings.Replace(remaining, shortHash, "", 1)
-	}
-
-	// Extract version number
-	numRe := regexp.MustCompile(`\W(\d+)(\W|$)`)
-	nums := numRe.FindStringSubmatch(remaining)
-
-	pythonPreSuffix := "0" // Default for PEP440 compliance
-	if len(nums) == 3 {
-		pythonPreSuffix = nums[1]
-	}
-
-	pythonPreVersion := ""
-	if prefix != "" {
-		pythonPreVersion = fmt.Sprintf("%s%s", prefix, pythonPreSuffix)
-	}
-
-	if isDirty {
-		pythonPreVersion += "+dirty"
-	}
-
-	return pythonPreVersion, nil
+	return strconv.FormatInt(components.Timestamp.UTC().Unix(), 10)
 }
 
-func getPythonPrePrefix(preVersion string) (string, string) {
-	switch {
-	case strings.HasPrefix(preVersion, "-dev"):
-		return "dev", preVersion[4:]
-	case strings.HasPrefix(preVersion, "-alpha"):
-		return "a", preVersion[6:]
-	case strings.HasPrefix(preVersion, "-beta"):
-		return "b", preVersion[5:]
-	case strings.HasPrefix(preVersion, "-rc"):
-		return "rc", preVersion[3:]
-	default:
-		return "", preVersion
-	}
+// verbatim reports whether the version is used as it is, without the
+// pre-release number and snapshot forms of builds past a tag
+func (c *VersionComponents) verbatim() bool {
+	return c.IsExact || c.Forced
 }
 
 // GenerateFallbackVersion creates a default development version when git is unavailable
@@ -260,5 +311,23 @@ func GenerateFallbackVersion() *LanguageVersions {
 		JavaScript: "v0.0.0-dev",
 		DotNet:     "0.0.0-dev",
 		Go:         "v0.0.0-dev",
+		Maven:      "0.0.0-SNAPSHOT",
+		Debian:     "0.0.0~dev",
+		RPM:        "0.0.0~dev",
+		RPMRelease: "1",
+		Docker:     "0.0.0-dev",
+		NPM:        "0.0.0-dev",
+		RubyGems:   "0.0.0.dev",
+		Cargo:      "0.0.0-dev",
+
+		Helm:           "0.0.0-dev",
+		HelmAppVersion: "0.0.0-dev",
+
+		Version: &Version{Version: semver.MustParse("0.0.0-dev")},
+
+		DotNetAssembly:      "0.0.0.0",
+		DotNetFile:          "0.0.0.0",
+		DotNetInformational: "0.0.0-dev",
+		NuGetV1:             "0.0.0-dev",
 	}
 }
@@ -1,9 +1,16 @@
 package vers
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/stretchr/testify/require"
 )
 
@@ -268,3 +275,113 @@ func TestGenerateFallbackVersion(t *testing.T) {
 	require.Equal(t, "0.0.0-dev", version.DotNet)
 	require.Equal(t, "v0.0.0-dev", version.Go)
 }
+
+func TestCalculateEmptyRepository(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	t.Run("No commits", func(t *testing.T) {
+		_, err := Calculate(Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+		require.ErrorIs(t, err, ErrNoCommits)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		versions, err := Calculate(Options{Repository: repo, Commitish: plumbing.Revision("HEAD"), Override: "1.0.0"})
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", versions.SemVer)
+	})
+}
+
+// testRepoLongHistory creates a linear history of n commits sharing an
+// empty tree, tagging the root commit v1.0.0
+func testRepoLongHistory(t *testing.T, n int) *git.Repository {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	// Trees and commits are stored directly, which is much faster than
+	// committing through a worktree
+	storeObject := func(encode func(plumbing.EncodedObject) error) plumbing.Hash {
+		obj := repo.Storer.NewEncodedObject()
+		require.NoError(t, encode(obj))
+		hash, err := repo.Storer.SetEncodedObject(obj)
+		require.NoError(t, err)
+		return hash
+	}
+
+	tree := storeObject((&object.Tree{}).Encode)
+
+	var head plumbing.Hash
+	for i := 0; i < n; i++ {
+		commit := &object.Commit{
+			Author:    *testSignature,
+			Committer: *testSignature,
+			Message:   fmt.Sprintf("Commit %d", i),
+			TreeHash:  tree,
+		}
+		if i > 0 {
+			commit.ParentHashes = []plumbing.Hash{head}
+		}
+		head = storeObject(commit.Encode)
+
+		if i == 0 {
+			_, err = repo.CreateTag("v1.0.0", head, nil)
+			require.NoError(t, err)
+		}
+	}
+
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.Master, head)))
+	return repo
+}
+
+func TestCalculateContext(t *testing.T) {
+	repo := testRepoLongHistory(t, 5000)
+
+	t.Run("Complete", func(t *testing.T) {
+		versions, err := CalculateContext(context.Background(), Options{Repository: repo, Commitish: plumbing.Revision("HEAD")})
+		require.NoError(t, err)
+		require.Contains(t, versions.SemVer, "1.1.0-alpha.")
+	})
+
+	t.Run("Already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := CalculateContext(ctx, Options{Repository: repo})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Cancelled during history walk", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The filter runs once per commit walked, so cancel after 100 commits
+		calls := 0
+		filter := func(tag string) bool {
+			calls++
+			if calls == 100 {
+				cancel()
+			}
+			return false
+		}
+
+		_, _, err := CalculateWithComponentsContext(ctx, Options{Repository: repo, TagFilter: filter})
+		require.ErrorIs(t, err, context.Canceled)
+		require.LessOrEqual(t, calls, 101)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Slow enough that walking the whole history would take seconds
+		filter := func(tag string) bool {
+			time.Sleep(time.Millisecond)
+			return true
+		}
+
+		start := time.Now()
+		_, err := CalculateContext(ctx, Options{Repository: repo, TagFilter: filter})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), time.Second)
+	})
+}